@@ -22,9 +22,41 @@ type ConfigMxObj struct {
 	ConcurrencyLimitLookupMX uint32
 }
 
+type ConfigParseObj struct {
+	DisableIDN         bool
+	EAI                bool
+	Quoted             bool
+	Literals           bool
+	RejectPrivateIP    bool
+	StrictTLD          bool
+	RejectPublicSuffix bool
+	RejectSpecialUse   bool
+	Providers          bool // apply per-provider local-part rules (AddProviderRule)
+	HTML5              bool // local part as in HTML5 input[type=email]: leading, trailing and repeated dots allowed
+
+	LenMax      int // whole address, 1..254; 0 means 254
+	LoginLenMax int // local part with tags, 1..64; 0 means 64
+}
+
+func (p *ConfigParseObj) lenMax() int {
+	if p.LenMax <= 0 || p.LenMax > 254 {
+		return 254
+	}
+	return p.LenMax
+}
+
+func (p *ConfigParseObj) loginLenMax() int {
+	if p.LoginLenMax <= 0 || p.LoginLenMax > 64 {
+		return 64
+	}
+	return p.LoginLenMax
+}
+
 type ConfigObj struct {
-	NoCache bool
-	MX      ConfigMxObj
+	NoCache   bool
+	CacheSize uint32 // parsed results kept by New / NewFast; 0 means 10 000
+	MX        ConfigMxObj
+	Parse     ConfigParseObj
 
 	Ctx context.Context
 }
@@ -32,7 +64,8 @@ type ConfigObj struct {
 // //
 
 var DefaultConfig = &ConfigObj{
-	NoCache: true,
+	NoCache:   true,
+	CacheSize: 10_000,
 	MX: ConfigMxObj{
 		TllPos:       6 * time.Hour,
 		TllNeg:       15 * time.Minute,
@@ -50,3 +83,19 @@ var DefaultConfig = &ConfigObj{
 
 	Ctx: context.Background(),
 }
+
+// Parser profiles: assign one to ConfigObj.Parse or pass it to NewWith / NewFastWith.
+var (
+	// ProfilePragmatic is today's default grammar.
+	ProfilePragmatic = ConfigParseObj{}
+
+	// ProfileStrict accepts what a browser accepts in input[type=email] (ASCII only, dots anywhere
+	// in the local part, no quoted strings or literals), restricted to real, registrable, public domains.
+	ProfileStrict = ConfigParseObj{HTML5: true, DisableIDN: true, StrictTLD: true, RejectPublicSuffix: true, RejectSpecialUse: true}
+
+	// ProfileRFC5321 adds quoted local parts and address literals.
+	ProfileRFC5321 = ConfigParseObj{Quoted: true, Literals: true}
+
+	// ProfileProvider enforces the sign-up rules of large mailbox providers (Gmail: 6–30 chars…).
+	ProfileProvider = ConfigParseObj{StrictTLD: true, Providers: true}
+)
@@ -0,0 +1,124 @@
+package puremail
+
+import (
+	"bufio"
+	"embed"
+	"io"
+	"strings"
+	"sync"
+)
+
+// // // // // // // // // //
+
+//go:embed data
+var dataFS embed.FS
+
+type setObj struct {
+	mu   sync.RWMutex
+	data map[string]struct{}
+	key  func(string) string
+}
+
+func newSetObj(file string) *setObj {
+	return newSetKeyObj(file, setKey)
+}
+
+func newSetKeyObj(file string, key func(string) string) *setObj {
+	s := &setObj{data: make(map[string]struct{}, 1024), key: key}
+
+	f, err := dataFS.Open(file)
+	if err != nil {
+		panic("puremail: missing embedded " + file)
+	}
+	defer f.Close()
+
+	if err = s.load(f); err != nil {
+		panic("puremail: broken embedded " + file)
+	}
+	return s
+}
+
+func setKey(s string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), ".")
+}
+
+func (s *setObj) add(items ...string) {
+	s.mu.Lock()
+	for _, item := range items {
+		if item = s.key(item); item != "" {
+			s.data[item] = struct{}{}
+		}
+	}
+	s.mu.Unlock()
+}
+
+func (s *setObj) read(r io.Reader) ([]string, error) {
+	items := make([]string, 0, 1024)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items, sc.Err()
+}
+
+func (s *setObj) load(r io.Reader) error {
+	items, err := s.read(r)
+	if err != nil {
+		return err
+	}
+
+	s.add(items...)
+	return nil
+}
+
+func (s *setObj) replace(r io.Reader) error {
+	items, err := s.read(r)
+	if err != nil {
+		return err
+	}
+
+	data := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if item = s.key(item); item != "" {
+			data[item] = struct{}{}
+		}
+	}
+
+	// readers see either the old or the new set, never a partial one
+	s.mu.Lock()
+	s.data = data
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *setObj) has(item string) bool {
+	s.mu.RLock()
+	_, ok := s.data[item]
+	s.mu.RUnlock()
+	return ok
+}
+
+// matchSuffix reports the longest entry equal to domain or to one of its parent domains.
+func (s *setObj) matchSuffix(domain string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for {
+		if _, ok := s.data[domain]; ok {
+			return domain, true
+		}
+
+		i := strings.IndexByte(domain, '.')
+		if i < 0 {
+			return "", false
+		}
+		domain = domain[i+1:]
+	}
+}
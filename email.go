@@ -1,7 +1,7 @@
 package puremail
 
 import (
-	"golang.org/x/sync/singleflight"
+	"net/netip"
 )
 
 // // // // // // // // // //
@@ -21,31 +21,42 @@ func (p *EmailPrefixObj) Prefix() byte {
 
 type EmailObj struct {
 	login, domain string
+	quoted        string
+	ip            netip.Addr
 	prefixes      []EmailPrefixObj
 	len           int
+	utf8          bool
 
 	conf *ConfigObj
 }
 
 //
 
-var (
-	parseGroup singleflight.Group
-	conf       *ConfigObj
-)
+var conf *ConfigObj
+
+func parseConf() *ConfigParseObj {
+	if conf == nil {
+		return &DefaultConfig.Parse
+	}
+	return &conf.Parse
+}
 
 func doParse(mail string, fast bool) (*EmailObj, error) {
 	if conf.NoCache {
 		return parse(mail, fast)
 	}
 
-	v, err, _ := parseGroup.Do(mail, func() (any, error) {
-		return parse(mail, fast)
-	})
-	if err != nil {
-		return nil, err
+	key := parseKeyObj{mail: mail, fast: fast}
+	if obj, err, ok := parseCache.get(key); ok {
+		return obj, err
 	}
-	return v.(*EmailObj), nil
+
+	gen := parseCache.generation()
+	obj, err := parse(mail, fast)
+	if len(mail) <= parseConf().lenMax() {
+		parseCache.put(key, obj, err, gen)
+	}
+	return obj, err
 }
 
 //
@@ -54,6 +65,7 @@ func Init(configuration *ConfigObj) {
 	copyConf := *configuration
 	conf = &copyConf
 
+	parseCacheInit(&copyConf)
 	mxInitValue(&copyConf)
 }
 
@@ -63,3 +75,12 @@ func InitDefault() {
 
 func New(mail string) (*EmailObj, error)     { return doParse(mail, false) }
 func NewFast(mail string) (*EmailObj, error) { return doParse(mail, true) }
+
+// NewWith parses with the given profile instead of ConfigObj.Parse; results are not cached.
+func NewWith(mail string, profile ConfigParseObj) (*EmailObj, error) {
+	return parseWith(mail, false, &profile)
+}
+
+func NewFastWith(mail string, profile ConfigParseObj) (*EmailObj, error) {
+	return parseWith(mail, true, &profile)
+}
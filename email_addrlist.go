@@ -0,0 +1,163 @@
+package puremail
+
+import (
+	"strconv"
+	"strings"
+)
+
+// // // // // // // // // //
+
+// AddressError reports one entry of an address list that could not be parsed.
+type AddressError struct {
+	Index int    // position of the entry in the list, groups included
+	Raw   string // entry text as written
+	Group string // enclosing group name, if any
+	Err   error
+}
+
+func (e *AddressError) Error() string {
+	return "address " + strconv.Itoa(e.Index) + " " + strconv.Quote(e.Raw) + ": " + e.Err.Error()
+}
+
+func (e *AddressError) Unwrap() error { return e.Err }
+
+//
+
+type addrItemObj struct {
+	raw   string
+	group string
+	err   error
+}
+
+// splitAddressList cuts a header value at top-level commas, skipping quoted strings, comments,
+// angle-addrs and literals, and unwraps `name: a, b;` groups.
+func splitAddressList(s string) (items []addrItemObj) {
+	var (
+		start   int
+		group   string
+		inGroup bool
+		bad     error
+		angle   bool
+		literal bool
+	)
+
+	push := func(end int) {
+		raw := strings.TrimSpace(s[start:end])
+		if raw != "" || bad != nil {
+			items = append(items, addrItemObj{raw: raw, group: group, err: bad})
+		}
+		start, bad = end+1, nil
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			end, _, ok := scanQuoted(s, i)
+			if !ok {
+				bad, i = ErrInvalidMailbox, len(s)
+				continue
+			}
+			i = end
+
+		case c == '(':
+			end, _, ok := scanComment(s, i)
+			if !ok {
+				bad, i = ErrInvalidMailbox, len(s)
+				continue
+			}
+			i = end
+
+		case c == '<':
+			angle = true
+		case c == '>':
+			angle = false
+		case c == '[':
+			literal = true
+		case c == ']':
+			literal = false
+
+		case angle || literal:
+
+		case c == ',':
+			push(i)
+
+		case c == ':':
+			if inGroup {
+				bad = ErrInvalidMailbox
+				continue
+			}
+			words, ok := scanPhrase(s[start:i])
+			if !ok || len(words) == 0 {
+				bad = ErrInvalidMailbox
+				continue
+			}
+			group, inGroup, start = decodePhrase(words), true, i+1
+
+		case c == ';':
+			if !inGroup {
+				bad = ErrInvalidMailbox
+				continue
+			}
+			push(i)
+			group, inGroup = "", false
+		}
+	}
+	push(len(s))
+	return
+}
+
+// scanPhrase splits a display-name phrase into words, unquoting quoted strings and dropping comments.
+func scanPhrase(s string) (words []string, ok bool) {
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			end, text, good := scanQuoted(s, i)
+			if !good {
+				return nil, false
+			}
+			word.WriteString(text)
+			i = end
+		case '(':
+			end, _, good := scanComment(s, i)
+			if !good {
+				return nil, false
+			}
+			flush()
+			i = end
+		case ' ', '\t', '\r', '\n':
+			flush()
+		case '<', '>', '@', ',', ';', ':':
+			return nil, false
+		default:
+			word.WriteByte(c)
+		}
+	}
+	flush()
+	return words, true
+}
+
+// ParseAddressList parses a To/Cc style header value: `a@x.io, "B" <b@y.io>, team: c@z.io, d@z.io;`.
+// Entries that fail are reported in errs and do not discard the rest; empty groups yield nothing.
+func ParseAddressList(s string) (list []*MailboxObj, errs []*AddressError) {
+	for i, it := range splitAddressList(s) {
+		err := it.err
+		if err == nil {
+			var m *MailboxObj
+			if m, err = ParseMailbox(it.raw); err == nil {
+				m.group = it.group
+				list = append(list, m)
+				continue
+			}
+		}
+		errs = append(errs, &AddressError{Index: i, Raw: it.raw, Group: it.group, Err: err})
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package puremail
+
+import "unsafe"
+
+// // // // // // // // // //
+
+// internObj stores parsed strings either on the heap or, with an arena, inside caller-owned memory.
+type internObj struct {
+	arena *[]byte
+}
+
+func (in internObj) string(b []byte) string {
+	if in.arena == nil {
+		return string(b)
+	}
+	if len(b) == 0 {
+		return ""
+	}
+
+	start := len(*in.arena)
+	*in.arena = append(*in.arena, b...)
+	return unsafe.String(&(*in.arena)[start], len(b))
+}
+
+// bytesString views b as a string for the duration of a parse; parse never retains its input.
+func bytesString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
+
+//
+
+// NewBytes is New for input that already sits in a []byte (CSV rows, queue messages) without the
+// string conversion. b may be reused as soon as it returns. Results are not cached.
+func NewBytes(b []byte) (*EmailObj, error) {
+	return parseWith(bytesString(b), false, parseConf())
+}
+
+func NewFastBytes(b []byte) (*EmailObj, error) {
+	return parseWith(bytesString(b), true, parseConf())
+}
+
+// AppendParse parses b into obj and appends the login, domain and tag texts to arena, returning
+// the extended arena; the strings of obj point into it. Reusing obj and arena[:0] for the next batch
+// makes parsing allocation-free, but the previous results must no longer be in use by then.
+// IDN domains, EAI and quoted local parts still allocate their normalised forms.
+//
+// Login, Domain, Prefixes, PublicSuffix and RegistrableDomain return views of the arena as well;
+// Mail, MailFull and Bytes build new strings. HasMX and IsDisposableMX copy the domain into the MX cache.
+func AppendParse(arena []byte, obj *EmailObj, b []byte) ([]byte, error) {
+	err := parseInto(obj, bytesString(b), false, parseConf(), internObj{arena: &arena})
+	return arena, err
+}
@@ -0,0 +1,168 @@
+package puremail
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// // // // // // // // // //
+
+type parseKeyObj struct {
+	mail string
+	fast bool
+}
+
+type parseEntryObj struct {
+	key        parseKeyObj
+	obj        *EmailObj
+	err        error
+	prev, next *parseEntryObj
+}
+
+// parseCacheObj is a bounded LRU of parse results, failures included. Stored objects and parse
+// errors are never handed out: every hit returns a copy, so callers cannot corrupt each other's.
+type parseCacheObj struct {
+	mu       sync.Mutex
+	items    map[parseKeyObj]*parseEntryObj
+	root     parseEntryObj // root.next is the most recently used entry
+	capacity int
+	gen      uint64 // bumped by purge, so results computed from older lists are not stored
+
+	hits, misses atomic.Uint64
+}
+
+type CacheStatsObj struct {
+	Hits, Misses uint64
+	Size         int
+	Capacity     int
+}
+
+var parseCache *parseCacheObj
+
+func newParseCacheObj(capacity int) *parseCacheObj {
+	c := &parseCacheObj{items: make(map[parseKeyObj]*parseEntryObj, min(capacity, 1024)), capacity: capacity}
+	c.root.prev, c.root.next = &c.root, &c.root
+	return c
+}
+
+func parseCacheInit(conf *ConfigObj) {
+	if conf.CacheSize == 0 {
+		conf.CacheSize = 10_000
+	}
+	parseCache = newParseCacheObj(int(conf.CacheSize))
+}
+
+func (c *parseCacheObj) unlink(e *parseEntryObj) {
+	e.prev.next, e.next.prev = e.next, e.prev
+}
+
+func (c *parseCacheObj) pushFront(e *parseEntryObj) {
+	e.prev, e.next = &c.root, c.root.next
+	e.next.prev = e
+	c.root.next = e
+}
+
+func (c *parseCacheObj) get(key parseKeyObj) (*EmailObj, error, bool) {
+	c.mu.Lock()
+	e, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		c.misses.Add(1)
+		return nil, nil, false
+	}
+	c.unlink(e)
+	c.pushFront(e)
+	obj, err := e.obj, e.err
+	c.mu.Unlock()
+
+	c.hits.Add(1)
+	if obj != nil {
+		obj = obj.clone()
+	}
+	if pe, ok := err.(*ParseError); ok {
+		cp := *pe
+		err = &cp
+	}
+	return obj, err, true
+}
+
+func (c *parseCacheObj) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// put stores its own copy of obj, evicting the least recently used entry when full. A result
+// parsed before the last purge (gen is stale) is dropped.
+func (c *parseCacheObj) put(key parseKeyObj, obj *EmailObj, err error, gen uint64) {
+	if obj != nil {
+		obj = obj.clone()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	if e, ok := c.items[key]; ok {
+		e.obj, e.err = obj, err
+		c.unlink(e)
+		c.pushFront(e)
+		return
+	}
+
+	var e *parseEntryObj
+	if len(c.items) >= c.capacity {
+		e = c.root.prev
+		c.unlink(e)
+		delete(c.items, e.key)
+	} else {
+		e = new(parseEntryObj)
+	}
+
+	*e = parseEntryObj{key: key, obj: obj, err: err}
+	c.items[key] = e
+	c.pushFront(e)
+}
+
+func (c *parseCacheObj) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.items)
+	c.root.prev, c.root.next = &c.root, &c.root
+	c.gen++
+}
+
+func (obj *EmailObj) clone() *EmailObj {
+	c := *obj
+	c.prefixes = slices.Clone(obj.prefixes)
+	return &c
+}
+
+//
+
+// CacheStats reports the New / NewFast result cache; all zero when ConfigObj.NoCache is set.
+func CacheStats() CacheStatsObj {
+	c := parseCache
+	if c == nil || conf.NoCache {
+		return CacheStatsObj{}
+	}
+
+	c.mu.Lock()
+	size := len(c.items)
+	c.mu.Unlock()
+
+	return CacheStatsObj{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: size, Capacity: c.capacity}
+}
+
+// PurgeCache drops every cached result. LoadTLD, LoadPSL, AddSpecialUse, LoadSpecialUse,
+// AddProviderRule and RemoveProviderRule call it themselves, as they change what parses.
+func PurgeCache() {
+	if c := parseCache; c != nil {
+		c.purge()
+	}
+}
@@ -0,0 +1,140 @@
+package puremail
+
+import (
+	"io"
+	"strings"
+	"sync"
+)
+
+// // // // // // // // // //
+
+type EmailClass uint8
+
+const (
+	ClassCorporate EmailClass = 1 << iota
+	ClassFree
+	ClassDisposable
+	ClassEducation
+	ClassGovernment
+	ClassRole
+)
+
+var classNames = [...]string{"corporate", "free", "disposable", "education", "government", "role"}
+
+func (c EmailClass) Has(flag EmailClass) bool { return c&flag == flag }
+
+func (c EmailClass) String() string {
+	var b strings.Builder
+	for i, name := range classNames {
+		if c&(1<<i) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(name)
+	}
+	return b.String()
+}
+
+//
+
+type ClassifierFunc func(obj *EmailObj) EmailClass
+
+var (
+	freeSet       = newSetObj("data/freemail.txt")
+	educationSet  = newSetObj("data/education.txt")
+	governmentSet = newSetObj("data/government.txt")
+
+	classifiersMu sync.RWMutex
+	classifiers   []ClassifierFunc
+)
+
+func classSet(class EmailClass) *setObj {
+	switch class {
+	case ClassFree:
+		return freeSet
+	case ClassDisposable:
+		return disposableSet
+	case ClassEducation:
+		return educationSet
+	case ClassGovernment:
+		return governmentSet
+	default:
+		return nil
+	}
+}
+
+func AddClassDomains(class EmailClass, domains ...string) error {
+	set := classSet(class)
+	if set == nil {
+		return ErrUnknownClass
+	}
+	set.add(domains...)
+	return nil
+}
+
+func LoadClassDomains(class EmailClass, r io.Reader) error {
+	set := classSet(class)
+	if set == nil {
+		return ErrUnknownClass
+	}
+	return set.load(r)
+}
+
+// AddClassifier registers a hook whose result is merged into every Classify call.
+func AddClassifier(fn ClassifierFunc) {
+	classifiersMu.Lock()
+	classifiers = append(classifiers, fn)
+	classifiersMu.Unlock()
+}
+
+// registrableIn reports whether the registrable domain (mail.gmail.com → gmail.com) is in set.
+func registrableIn(set *setObj, domain string) bool {
+	if reg := registrableDomain(domain); reg != "" {
+		domain = reg
+	}
+	return set.has(domain)
+}
+
+// sectorIn also matches the public suffix (ox.ac.uk → ac.uk, cabinet.gov.ua → gov.ua).
+func sectorIn(set *setObj, domain string) bool {
+	if registrableIn(set, domain) {
+		return true
+	}
+	_, ok := set.matchSuffix(publicSuffix(domain))
+	return ok
+}
+
+//
+
+func (obj *EmailObj) Classify() (c EmailClass) {
+	if registrableIn(freeSet, obj.domain) {
+		c |= ClassFree
+	}
+	if obj.IsDisposable() {
+		c |= ClassDisposable
+	}
+	if sectorIn(educationSet, obj.domain) {
+		c |= ClassEducation
+	}
+	if sectorIn(governmentSet, obj.domain) {
+		c |= ClassGovernment
+	}
+	if obj.IsRole() {
+		c |= ClassRole
+	}
+
+	classifiersMu.RLock()
+	hooks := classifiers
+	classifiersMu.RUnlock()
+
+	for _, fn := range hooks {
+		c |= fn(obj)
+	}
+
+	if c&(ClassFree|ClassDisposable|ClassEducation|ClassGovernment) == 0 {
+		c |= ClassCorporate
+	}
+	return
+}
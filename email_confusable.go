@@ -0,0 +1,231 @@
+package puremail
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// // // // // // // // // //
+
+type confusablesObj struct {
+	mu   sync.RWMutex
+	data map[rune]string
+}
+
+var confusables = newConfusablesObj("data/confusables.txt")
+
+func newConfusablesObj(file string) *confusablesObj {
+	c := &confusablesObj{data: make(map[rune]string, 512)}
+
+	f, err := dataFS.Open(file)
+	if err != nil {
+		panic("puremail: missing embedded " + file)
+	}
+	defer f.Close()
+
+	if err = c.load(f); err != nil {
+		panic("puremail: broken embedded " + file)
+	}
+	return c
+}
+
+func parseCodePoints(s string) (string, error) {
+	var b strings.Builder
+	for _, field := range strings.Fields(s) {
+		cp, err := strconv.ParseUint(field, 16, 32)
+		if err != nil {
+			return "", err
+		}
+		b.WriteRune(rune(cp))
+	}
+	return b.String(), nil
+}
+
+// load reads the TR39 confusables.txt format: "<source> ; <target> ; <type> # comment".
+func (c *confusablesObj) load(r io.Reader) error {
+	items := make(map[rune]string, 512)
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimPrefix(sc.Text(), "\ufeff")
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Split(line, ";")
+		if len(fields) < 2 {
+			continue
+		}
+
+		src, err := parseCodePoints(fields[0])
+		if err != nil {
+			return err
+		}
+		dst, err := parseCodePoints(fields[1])
+		if err != nil {
+			return err
+		}
+
+		r, size := utf8.DecodeRuneInString(src)
+		if size == 0 || size != len(src) {
+			continue
+		}
+		items[r] = strings.ToLower(dst)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	for k, v := range items {
+		c.data[k] = v
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+func LoadConfusables(r io.Reader) error { return confusables.load(r) }
+
+// skeleton follows UTS #39: NFD, prototype mapping, NFD again; the result is lower-cased.
+func skeleton(s string) string {
+	s = norm.NFD.String(s)
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	confusables.mu.RLock()
+	for _, r := range s {
+		if proto, ok := confusables.data[r]; ok {
+			b.WriteString(proto)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	confusables.mu.RUnlock()
+
+	return strings.ToLower(norm.NFD.String(b.String()))
+}
+
+//
+
+const (
+	scriptLatin uint16 = 1 << iota
+	scriptCyrillic
+	scriptGreek
+	scriptArmenian
+	scriptHan
+	scriptHiragana
+	scriptKatakana
+	scriptBopomofo
+	scriptHangul
+	scriptOther
+)
+
+var scriptTables = [...]struct {
+	bit   uint16
+	table *unicode.RangeTable
+}{
+	{scriptLatin, unicode.Latin},
+	{scriptCyrillic, unicode.Cyrillic},
+	{scriptGreek, unicode.Greek},
+	{scriptArmenian, unicode.Armenian},
+	{scriptHan, unicode.Han},
+	{scriptHiragana, unicode.Hiragana},
+	{scriptKatakana, unicode.Katakana},
+	{scriptBopomofo, unicode.Bopomofo},
+	{scriptHangul, unicode.Hangul},
+}
+
+// scriptSet ignores Common and Inherited characters (digits, punctuation, combining marks).
+func scriptSet(s string) (set uint16) {
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' {
+				set |= scriptLatin
+			}
+			continue
+		}
+		if unicode.In(r, unicode.Common, unicode.Inherited) {
+			continue
+		}
+
+		bit := scriptOther
+		for _, st := range scriptTables {
+			if unicode.Is(st.table, r) {
+				bit = st.bit
+				break
+			}
+		}
+		set |= bit
+	}
+	return
+}
+
+// isMixedScript applies the UTS #39 "highly restrictive" level: one script, or Latin
+// combined with the CJK sets that are customarily written together.
+func isMixedScript(s string) bool {
+	set := scriptSet(s)
+	if set&(set-1) == 0 {
+		return false
+	}
+
+	for _, allowed := range [...]uint16{
+		scriptLatin | scriptHan | scriptHiragana | scriptKatakana,
+		scriptLatin | scriptHan | scriptBopomofo,
+		scriptLatin | scriptHan | scriptHangul,
+	} {
+		if set&^allowed == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// isWholeScriptConfusable reports non-Latin text whose skeleton is plain ASCII ("аррӏе" → "apple").
+func isWholeScriptConfusable(s string) bool {
+	set := scriptSet(s)
+	if set == 0 || set&scriptLatin != 0 {
+		return false
+	}
+	return isASCII(skeleton(s))
+}
+
+func isHomographPart(s string) bool {
+	if isASCII(s) {
+		return false
+	}
+	return isMixedScript(s) || isWholeScriptConfusable(s)
+}
+
+//
+
+// Skeleton returns the UTS #39 skeleton of the address; addresses that look alike share it.
+func (obj *EmailObj) Skeleton() string {
+	return skeleton(obj.login) + "@" + skeleton(obj.DomainUnicode())
+}
+
+// HomographRisk flags a local part or domain label that mixes scripts or imitates Latin text.
+// The TLD is skipped: it is registry-controlled and "укр" is not a spoof of "ykp".
+func (obj *EmailObj) HomographRisk() bool {
+	if isHomographPart(obj.login) {
+		return true
+	}
+
+	domain := obj.DomainUnicode()
+	if i := strings.LastIndexByte(domain, '.'); i >= 0 {
+		domain = domain[:i]
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if isHomographPart(label) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package puremail
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// // // // // // // // // //
+
+type Deobfuscation uint8
+
+const (
+	DeobfuscateHTML   Deobfuscation = 1 << iota // HTML entities decoded (&#64;, &commat;)
+	DeobfuscateAt                               // "[at]", "(at)", " at ", "собака" → "@"
+	DeobfuscateDot                              // "[dot]", "(dot)", " dot ", "точка" → "."
+	DeobfuscateSpaces                           // whitespace around the parts removed
+)
+
+var deobfuscationNames = [...]string{"html", "at", "dot", "spaces"}
+
+func (d Deobfuscation) Has(flag Deobfuscation) bool { return d&flag == flag }
+
+func (d Deobfuscation) String() string {
+	var b strings.Builder
+	for i, name := range deobfuscationNames {
+		if d&(1<<i) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString(name)
+	}
+	return b.String()
+}
+
+//
+
+var (
+	obfuscationAtSet  = newSetObj("data/obfuscation_at.txt")
+	obfuscationDotSet = newSetObj("data/obfuscation_dot.txt")
+)
+
+func AddObfuscationAt(words ...string)     { obfuscationAtSet.add(words...) }
+func AddObfuscationDot(words ...string)    { obfuscationDotSet.add(words...) }
+func LoadObfuscationAt(r io.Reader) error  { return obfuscationAtSet.load(r) }
+func LoadObfuscationDot(r io.Reader) error { return obfuscationDotSet.load(r) }
+
+// obfuscatedWord maps a bracketed or free-standing word to "@" or ".".
+func obfuscatedWord(word string) (string, Deobfuscation) {
+	switch word = strings.ToLower(strings.TrimSpace(word)); {
+	case word == "@" || obfuscationAtSet.has(word):
+		return "@", DeobfuscateAt
+	case word == "." || obfuscationDotSet.has(word):
+		return ".", DeobfuscateDot
+	}
+	return "", 0
+}
+
+var obfuscationBrackets = map[byte]byte{'[': ']', '(': ')', '{': '}', '<': '>'}
+
+func deobfuscate(s string) (string, Deobfuscation) {
+	var applied Deobfuscation
+
+	if strings.IndexByte(s, '&') >= 0 {
+		if u := html.UnescapeString(s); u != s {
+			s, applied = u, DeobfuscateHTML
+		}
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if closing, ok := obfuscationBrackets[s[i]]; ok {
+			if end := strings.IndexByte(s[i+1:], closing); end > 0 {
+				if sym, flag := obfuscatedWord(s[i+1 : i+1+end]); flag != 0 {
+					b.WriteString(sym)
+					applied |= flag
+					i += end + 1
+					continue
+				}
+			}
+		}
+		b.WriteByte(s[i])
+	}
+
+	fields := strings.Fields(b.String())
+	if len(fields) < 2 {
+		return b.String(), applied
+	}
+
+	var words Deobfuscation
+	b.Reset()
+	for _, f := range fields {
+		if sym, flag := obfuscatedWord(f); flag != 0 && sym != f {
+			b.WriteString(sym)
+			words |= flag
+			continue
+		}
+		b.WriteString(f)
+	}
+
+	// Joining words is only safe when it yields exactly one "@" and a dotted domain.
+	out := b.String()
+	at := strings.IndexByte(out, '@')
+	if at < 0 || strings.Count(out, "@") != 1 || !strings.Contains(out[at:], ".") {
+		return strings.Join(fields, " "), applied
+	}
+	return out, applied | words | DeobfuscateSpaces
+}
+
+// Deobfuscate rewrites "alice [at] example [dot] io", "alice(at)example.io", "alice&#64;example.io"
+// and their localised forms into an address, parses it with New and reports what was rewritten.
+func Deobfuscate(s string) (*EmailObj, Deobfuscation, error) {
+	candidate, applied := deobfuscate(s)
+	obj, err := New(candidate)
+	return obj, applied, err
+}
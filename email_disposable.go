@@ -0,0 +1,51 @@
+package puremail
+
+import (
+	"io"
+)
+
+// // // // // // // // // //
+
+var (
+	disposableSet   = newSetObj("data/disposable.txt")
+	disposableMXSet = &setObj{data: make(map[string]struct{}), key: setKey}
+)
+
+func AddDisposable(domains ...string)    { disposableSet.add(domains...) }
+func LoadDisposable(r io.Reader) error   { return disposableSet.load(r) }
+func AddDisposableMX(hosts ...string)    { disposableMXSet.add(hosts...) }
+func LoadDisposableMX(r io.Reader) error { return disposableMXSet.load(r) }
+
+func isDisposableDomain(domain string) bool {
+	_, ok := disposableSet.matchSuffix(domain)
+	return ok
+}
+
+//
+
+func (obj *EmailObj) IsDisposable() bool {
+	return isDisposableDomain(obj.domain)
+}
+
+// IsDisposableMX additionally resolves the MX hosts of the domain and reports whether
+// any of them belongs to known disposable infrastructure. Results share the HasMX cache.
+func (obj *EmailObj) IsDisposableMX() (bool, error) {
+	if obj.IsDisposable() {
+		return true, nil
+	}
+
+	ent := mxResolve(obj.domain)
+	if ent.err != nil {
+		return false, ent.err
+	}
+
+	for _, host := range ent.hosts {
+		if isDisposableDomain(host) {
+			return true, nil
+		}
+		if _, ok := disposableMXSet.matchSuffix(host); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -6,6 +6,7 @@ import (
 	"golang.org/x/sync/singleflight"
 	"hash/crc32"
 	"net"
+	"strings"
 	"sync"
 	"time"
 )
@@ -25,16 +26,21 @@ type mxObj struct {
 }
 
 var (
-	errNoMX            = &net.DNSError{Err: ErrNilMX.Error(), IsNotFound: true}
-	errToManyLookupsMX = &net.DNSError{Err: ErrToManyLookups.Error(), IsNotFound: true}
-	lookupMX           = net.DefaultResolver.LookupMX
+	mxLiteral = &mxEntryObj{}
+	lookupMX  = net.DefaultResolver.LookupMX
 
 	mx *mxObj
 )
 
+// mxError reports err for domain as a *net.DNSError that errors.Is matches against err.
+func mxError(domain string, err error) *net.DNSError {
+	return &net.DNSError{Err: err.Error(), Name: domain, UnwrapErr: err, IsNotFound: true}
+}
+
 type mxEntryObj struct {
 	expire int64
 	err    error
+	hosts  []string
 }
 type mxShardCacheObj struct {
 	mu    sync.RWMutex
@@ -58,7 +64,6 @@ func mxInitValue(conf *ConfigObj) {
 		panic("timeout dns burst is too low")
 	}
 
-	ctx, _ := context.WithCancel(conf.Ctx)
 	confCopy := *conf
 
 	shardCounts := uint32(2)
@@ -74,7 +79,7 @@ func mxInitValue(conf *ConfigObj) {
 		shards:             make([]mxShardCacheObj, shardCounts),
 		maxEntriesPerShard: int(conf.MX.ShardMaxSize),
 
-		ctx:    ctx,
+		ctx:    conf.Ctx,
 		confMx: &confCopy.MX,
 	}
 
@@ -144,65 +149,75 @@ func nextTTL(positive bool) time.Duration {
 	return mx.confMx.TllNeg
 }
 
-func (obj *EmailObj) HasMX() error {
-	idx := crc32.ChecksumIEEE([]byte(obj.domain)) & (mx.shardCounts - 1)
+func mxResolve(domain string) *mxEntryObj {
+	if isLiteralDomain(domain) {
+		return mxLiteral
+	}
+	if isSpecialUseDomain(domain) {
+		return &mxEntryObj{err: mxError(strings.Clone(domain), ErrSpecialUse)}
+	}
+
+	idx := crc32.ChecksumIEEE([]byte(domain)) & (mx.shardCounts - 1)
 	sh := &mx.shards[int(idx)]
 
 	sh.mu.RLock()
-	ent, ok := sh.data[obj.domain]
+	ent, ok := sh.data[domain]
 	sh.mu.RUnlock()
 
+	// domain may point into an AppendParse arena: the cache and the flight group keep their own copy.
 	if ok {
 		if time.Now().UnixNano() < ent.expire {
 			if time.Until(time.Unix(0, ent.expire)) < mx.confMx.RefreshAhead && ent.err == nil {
 				sh.mu.Lock()
-				sh.data[obj.domain] = &mxEntryObj{err: ent.err, expire: time.Now().Add(nextTTL(ent.err == nil)).UnixNano()}
+				sh.data[strings.Clone(domain)] = &mxEntryObj{err: ent.err, hosts: ent.hosts, expire: time.Now().Add(nextTTL(ent.err == nil)).UnixNano()}
 				sh.mu.Unlock()
 			}
-			return ent.err
+			return ent
 		}
 	}
 
-	v, err, _ := sh.group.Do(obj.domain, func() (any, error) {
+	domain = strings.Clone(domain)
+	v, _, _ := sh.group.Do(domain, func() (any, error) {
 		sh.mu.RLock()
-		ent = sh.data[obj.domain]
+		ent = sh.data[domain]
 		sh.mu.RUnlock()
 		if ent != nil && time.Now().UnixNano() < ent.expire {
-			return ent.err, nil
+			return ent, nil
 		}
 
 		ctx, cancel := context.WithTimeout(mx.ctx, mx.confMx.TimeoutDnsBurst)
 		err := acquireDNS(ctx)
 		cancel()
 		if err != nil {
-			return errToManyLookupsMX, nil
+			return &mxEntryObj{err: mxError(domain, ErrToManyLookups)}, nil
 		}
 
 		ctx, cancel = context.WithTimeout(mx.ctx, mx.confMx.TimeoutDns)
-		mx, lookupErr := lookupMX(ctx, obj.domain)
+		records, lookupErr := lookupMX(ctx, domain)
 		cancel()
 		releaseDNS()
 
-		var entryErr error
-		if lookupErr != nil || len(mx) == 0 {
-			entryErr = errNoMX
+		ent = &mxEntryObj{}
+		if lookupErr != nil || len(records) == 0 {
+			ent.err = mxError(domain, ErrNilMX)
+		} else {
+			ent.hosts = make([]string, 0, len(records))
+			for _, r := range records {
+				ent.hosts = append(ent.hosts, setKey(r.Host))
+			}
 		}
+		ent.expire = time.Now().Add(nextTTL(ent.err == nil)).UnixNano()
 
 		sh.mu.Lock()
-		sh.data[obj.domain] = &mxEntryObj{err: entryErr, expire: time.Now().Add(nextTTL(entryErr == nil)).UnixNano()}
+		sh.data[domain] = ent
 		sh.mu.Unlock()
 
-		return entryErr, nil
+		return ent, nil
 	})
 
-	if err != nil {
-		return err
-	}
+	return v.(*mxEntryObj)
+}
 
-	err, ok = v.(error)
-	if ok {
-		return err
-	} else {
-		return nil
-	}
+func (obj *EmailObj) HasMX() error {
+	return mxResolve(obj.domain).err
 }
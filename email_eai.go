@@ -0,0 +1,70 @@
+package puremail
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// // // // // // // // // //
+
+func isUTF8LoginRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isLoginChar(byte(r))
+	}
+	if r == utf8.RuneError || unicode.IsSpace(r) || unicode.IsControl(r) {
+		return false
+	}
+	return !unicode.In(r, unicode.Cf, unicode.Co, unicode.Cs) && unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S)
+}
+
+// normalizeUTF8Login applies RFC 6532 rules: valid UTF-8, NFC, dot-atom structure.
+func normalizeUTF8Login(login string) (string, error) {
+	if !utf8.ValidString(login) {
+		return "", ErrInvalidLoginChars
+	}
+	login = norm.NFC.String(strings.ToLower(login))
+
+	segLen := 0
+	for _, r := range login {
+		if r == '.' {
+			if segLen == 0 {
+				return "", ErrInvalidLoginChars
+			}
+			segLen = 0
+			continue
+		}
+		if !isUTF8LoginRune(r) {
+			return "", ErrInvalidLoginChars
+		}
+		segLen++
+	}
+	if segLen == 0 {
+		return "", ErrInvalidLoginChars
+	}
+	return login, nil
+}
+
+// normalizeUTF8Quoted is the quoted-string variant: no dot-atom structure, only printable runes.
+func normalizeUTF8Quoted(login string) (string, error) {
+	if !utf8.ValidString(login) {
+		return "", ErrInvalidQuoted
+	}
+	login = norm.NFC.String(strings.ToLower(login))
+
+	for _, r := range login {
+		if r >= utf8.RuneSelf && !isUTF8LoginRune(r) {
+			return "", ErrInvalidQuoted
+		}
+	}
+	return login, nil
+}
+
+//
+
+// SMTPUTF8 reports that the local part is non-ASCII and the address needs an SMTPUTF8 transport (RFC 6531).
+func (obj *EmailObj) SMTPUTF8() bool {
+	return obj.utf8
+}
@@ -11,7 +11,7 @@ import (
 func (obj *EmailObj) String() string {
 	var b strings.Builder
 	b.WriteString("[ '")
-	b.WriteString(obj.login)
+	b.WriteString(obj.LoginQuoted())
 	b.WriteString("@")
 	b.WriteString(obj.domain)
 	b.WriteString("'")
@@ -33,17 +33,19 @@ func (obj *EmailObj) String() string {
 }
 
 func (obj *EmailObj) Bytes() []byte {
-	total := 1 + len(obj.login) + 1 + len(obj.domain) + 4
+	login := obj.LoginQuoted()
+
+	total := 1 + len(login) + 1 + len(obj.domain) + 4
 	for _, p := range obj.prefixes {
 		total += 1 + 1 + len(p.text)
 	}
 	buf := make([]byte, total)
 
 	i := 0
-	buf[i] = byte(len(obj.login))
+	buf[i] = byte(len(login))
 	i++
-	copy(buf[i:], obj.login)
-	i += len(obj.login)
+	copy(buf[i:], login)
+	i += len(login)
 
 	buf[i] = byte(len(obj.domain))
 	i++
@@ -84,6 +86,17 @@ func Decode(data []byte) (*EmailObj, error) {
 	}
 	obj := new(EmailObj)
 	obj.login = string(data[pos : pos+loginLen])
+	if obj.login[0] == '"' {
+		login, ok := unquoteLogin(obj.login)
+		if !ok {
+			return nil, ErrMalformed
+		}
+		obj.quoted, obj.login = obj.login, login
+	}
+	if controlIndex(obj.login) >= 0 {
+		return nil, ErrMalformed
+	}
+	obj.utf8 = !isASCII(obj.login)
 	pos += loginLen
 
 	if pos >= payloadLen {
@@ -95,6 +108,15 @@ func Decode(data []byte) (*EmailObj, error) {
 		return nil, ErrMalformed
 	}
 	obj.domain = string(data[pos : pos+domainLen])
+	if controlIndex(obj.domain) >= 0 {
+		return nil, ErrMalformed
+	}
+	if isLiteralDomain(obj.domain) {
+		var ok bool
+		if obj.ip, obj.domain, ok = parseLiteral(obj.domain); !ok {
+			return nil, ErrMalformed
+		}
+	}
 	pos += domainLen
 
 	if pos < payloadLen {
@@ -110,7 +132,10 @@ func Decode(data []byte) (*EmailObj, error) {
 
 			txtLen = int(data[pos])
 			pos++
-			if txtLen == 0 || pos+txtLen > payloadLen {
+			if pos+txtLen > payloadLen {
+				return nil, ErrMalformed
+			}
+			if prefix < ' ' || prefix == 0x7f || controlIndex(string(data[pos:pos+txtLen])) >= 0 {
 				return nil, ErrMalformed
 			}
 			obj.prefixes = append(obj.prefixes, EmailPrefixObj{char: prefix, text: string(data[pos : pos+txtLen])})
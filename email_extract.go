@@ -0,0 +1,149 @@
+package puremail
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// // // // // // // // // //
+
+// MatchObj is an address found in free text; Start and End are byte offsets of the match.
+type MatchObj struct {
+	Start, End int
+	Email      *EmailObj
+}
+
+func isExtractLoginChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '.' || c == '_' || c == '%' || c == '+' || c == '-' || c == '='
+}
+
+func isExtractDomainRune(r rune) bool {
+	if r < utf8.RuneSelf {
+		return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '-' || r == '.'
+	}
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+}
+
+// isAssetName catches "icon@2x.png" style names: a first label like "2x" or "3x".
+func isAssetName(domain string) bool {
+	label := domain[:max(strings.IndexByte(domain, '.'), 0)]
+	if len(label) < 2 || label[len(label)-1] != 'x' && label[len(label)-1] != 'X' {
+		return false
+	}
+	for i := 0; i < len(label)-1; i++ {
+		if label[i] < '0' || label[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// extractAt tries the candidate around the '@' at position at.
+func extractAt(text string, at int) (m MatchObj, next int, ok bool) {
+	start := at
+	for start > 0 && isExtractLoginChar(text[start-1]) {
+		start--
+	}
+	for start < at && text[start] == '.' {
+		start++
+	}
+
+	end := at + 1
+	for end < len(text) {
+		r, size := utf8.DecodeRuneInString(text[end:])
+		if !isExtractDomainRune(r) {
+			break
+		}
+		end += size
+	}
+	for end > at+1 && (text[end-1] == '.' || text[end-1] == '-') {
+		end--
+	}
+
+	if start == at || end == at+1 {
+		return m, at + 1, false
+	}
+	if start > 0 {
+		switch text[start-1] {
+		case '@', '/':
+			return m, end, false
+		case ':':
+			if !strings.HasSuffix(strings.ToLower(text[:start]), "mailto:") {
+				return m, end, false
+			}
+		}
+	}
+	if end < len(text) && text[end] == '@' {
+		return m, end + 1, false
+	}
+
+	domain := text[at+1 : end]
+	if !strings.Contains(domain, ".") || isAssetName(domain) {
+		return m, end, false
+	}
+
+	obj, err := New(text[start:end])
+	if err != nil || isLiteralDomain(obj.domain) || !isKnownTLD(obj.domain) {
+		return m, end, false
+	}
+	return MatchObj{Start: start, End: end, Email: obj}, end, true
+}
+
+// Extract finds addresses in prose, logs or CSV. Candidates are validated by New and must end in a
+// known top-level domain, which keeps "file@2x.png" and "user@localhost" out. Quoted local parts
+// and address literals are not searched for.
+func Extract(text string) (res []MatchObj) {
+	for i := 0; i < len(text); {
+		at := strings.IndexByte(text[i:], '@')
+		if at < 0 {
+			break
+		}
+
+		m, next, ok := extractAt(text, i+at)
+		if ok {
+			res = append(res, m)
+		}
+		i = next
+	}
+	return
+}
+
+// extractLineMax bounds the lines ExtractReader holds in memory.
+const extractLineMax = 1 << 20
+
+// scanLinesKeep is bufio.ScanLines that keeps the line terminator, so offsets add up.
+func scanLinesKeep(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// ExtractReader runs Extract line by line; offsets are relative to the start of the stream.
+// A line longer than 1 MiB stops the scan with bufio.ErrTooLong.
+func ExtractReader(r io.Reader) ([]MatchObj, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, extractLineMax)
+	sc.Split(scanLinesKeep)
+
+	var res []MatchObj
+	offset := 0
+	for sc.Scan() {
+		line := sc.Text()
+		for _, m := range Extract(line) {
+			m.Start += offset
+			m.End += offset
+			res = append(res, m)
+		}
+		offset += len(line)
+	}
+	return res, sc.Err()
+}
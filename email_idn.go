@@ -0,0 +1,63 @@
+package puremail
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// // // // // // // // // //
+
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.Transitional(false),
+	idna.VerifyDNSLength(true),
+)
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// isValidALabel decodes an "xn--" label and checks that it round-trips to the same A-label.
+func isValidALabel(label string) bool {
+	u, err := idnaProfile.ToUnicode(label)
+	if err != nil || u == label || isASCII(u) {
+		return false
+	}
+
+	a, err := idnaProfile.ToASCII(u)
+	return err == nil && a == label
+}
+
+func domainToASCII(domain string) (string, bool) {
+	a, err := idnaProfile.ToASCII(domain)
+	if err != nil {
+		return "", false
+	}
+	return a, true
+}
+
+func domainToUnicode(domain string) string {
+	if !strings.Contains(domain, "xn--") {
+		return domain
+	}
+
+	u, err := idnaProfile.ToUnicode(domain)
+	if err != nil {
+		return domain
+	}
+	return u
+}
+
+//
+
+// DomainUnicode returns the domain with A-labels decoded for display ("пример.укр").
+func (obj *EmailObj) DomainUnicode() string {
+	return domainToUnicode(obj.domain)
+}
@@ -0,0 +1,47 @@
+package puremail
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// // // // // // // // // //
+
+func isLiteralDomain(domain string) bool {
+	return len(domain) > 0 && domain[0] == '['
+}
+
+func isPrivateIP(ip netip.Addr) bool {
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// parseLiteral accepts "[192.0.2.1]" and "[IPv6:2001:db8::1]" (RFC 5321 address literals)
+// and returns the address with the canonical literal form.
+func parseLiteral(s string) (netip.Addr, string, bool) {
+	if len(s) < 3 || s[0] != '[' || s[len(s)-1] != ']' {
+		return netip.Addr{}, "", false
+	}
+	inner := s[1 : len(s)-1]
+
+	if len(inner) > 5 && strings.EqualFold(inner[:5], "ipv6:") {
+		ip, err := netip.ParseAddr(inner[5:])
+		if err != nil || !ip.Is6() || ip.Zone() != "" {
+			return netip.Addr{}, "", false
+		}
+		return ip, "[IPv6:" + ip.String() + "]", true
+	}
+
+	ip, err := netip.ParseAddr(inner)
+	if err != nil || !ip.Is4() {
+		return netip.Addr{}, "", false
+	}
+	return ip, "[" + ip.String() + "]", true
+}
+
+//
+
+// IP returns the address of a literal domain part; the zero Addr for regular domains.
+func (obj *EmailObj) IP() netip.Addr {
+	return obj.ip
+}
@@ -0,0 +1,244 @@
+package puremail
+
+import (
+	"errors"
+	"mime"
+	"strings"
+)
+
+// // // // // // // // // //
+
+type MailboxObj struct {
+	name  string
+	group string
+	email *EmailObj
+}
+
+var wordDecoder = new(mime.WordDecoder)
+
+func NewMailbox(name string, email *EmailObj) *MailboxObj {
+	return &MailboxObj{name: name, email: email}
+}
+
+func (m *MailboxObj) Name() string     { return m.name }
+func (m *MailboxObj) Group() string    { return m.group }
+func (m *MailboxObj) Email() *EmailObj { return m.email }
+
+//
+
+type mailboxScanObj struct {
+	phrase  []string
+	comment string
+	addr    string
+	addrAt  int // offset of addr in the scanned string
+	angle   bool
+}
+
+// scanMailbox splits `"Alice" (team) <alice@example.io>` into phrase words, the first comment and
+// the address; quoted strings are unescaped and comments dropped from the phrase. A quoted string
+// directly followed by '@' is the local part of a bare addr-spec and is kept as written.
+func scanMailbox(s string) (res mailboxScanObj, ok bool) {
+	var word strings.Builder
+	wordAt := 0
+	flush := func() {
+		if word.Len() > 0 {
+			if len(res.phrase) == 0 {
+				res.addrAt = wordAt
+			}
+			res.phrase = append(res.phrase, word.String())
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if word.Len() == 0 {
+			wordAt = i
+		}
+
+		switch {
+		case c == '"':
+			end, text, good := scanQuoted(s, i)
+			if !good {
+				return res, false
+			}
+			if res.angle {
+				return res, false
+			}
+			if end+1 < len(s) && s[end+1] == '@' {
+				text = s[i : end+1]
+			}
+			word.WriteString(text)
+			i = end
+
+		case c == '(':
+			end, text, good := scanComment(s, i)
+			if !good {
+				return res, false
+			}
+			if res.comment == "" {
+				res.comment = strings.TrimSpace(text)
+			}
+			flush()
+			i = end
+
+		case c == '<':
+			if res.angle {
+				return res, false
+			}
+			flush()
+			res.angle = true
+
+			j := i + 1
+			for ; j < len(s) && s[j] != '>'; j++ {
+				if s[j] == '"' {
+					end, _, good := scanQuoted(s, j)
+					if !good {
+						return res, false
+					}
+					j = end
+				}
+			}
+			if j >= len(s) {
+				return res, false
+			}
+			res.addr = strings.TrimSpace(s[i+1 : j])
+			res.addrAt = j - len(strings.TrimLeft(s[i+1:j], " \t\r\n"))
+			i = j
+
+		case c == '>':
+			return res, false
+
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			flush()
+
+		default:
+			if res.angle {
+				return res, false
+			}
+			word.WriteByte(c)
+		}
+	}
+	flush()
+
+	if !res.angle {
+		if len(res.phrase) != 1 {
+			return res, false
+		}
+		res.addr, res.phrase = res.phrase[0], nil
+	}
+	return res, res.addr != ""
+}
+
+func scanQuoted(s string, i int) (end int, text string, ok bool) {
+	var b strings.Builder
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+			if j >= len(s) {
+				return 0, "", false
+			}
+			b.WriteByte(s[j])
+		case '"':
+			return j, b.String(), true
+		default:
+			b.WriteByte(s[j])
+		}
+	}
+	return 0, "", false
+}
+
+func scanComment(s string, i int) (end int, text string, ok bool) {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return j, s[i+1 : j], true
+			}
+		}
+	}
+	return 0, "", false
+}
+
+func decodePhrase(words []string) string {
+	name := strings.Join(words, " ")
+	if decoded, err := wordDecoder.DecodeHeader(name); err == nil {
+		return decoded
+	}
+	return name
+}
+
+// ParseMailbox parses an RFC 5322 mailbox: `alice@example.io`, `Alice <alice@example.io>`,
+// `"Smith, Alice" <alice@example.io>` or `alice@example.io (Alice)`. Encoded words in the
+// display name are decoded (RFC 2047); the address goes through New, and the offsets of its
+// errors point into s.
+func ParseMailbox(s string) (*MailboxObj, error) {
+	trimmed := strings.TrimSpace(s)
+	res, ok := scanMailbox(trimmed)
+	if !ok {
+		return nil, ErrInvalidMailbox
+	}
+
+	email, err := New(res.addr)
+	if err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			shifted := *pe
+			shifted.Offset += strings.Index(s, trimmed) + res.addrAt
+			return nil, &shifted
+		}
+		return nil, err
+	}
+
+	name := decodePhrase(res.phrase)
+	if name == "" {
+		name = decodePhrase([]string{res.comment})
+	}
+	return &MailboxObj{name: name, email: email}, nil
+}
+
+//
+
+func isPhraseChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == ' ' ||
+		strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) >= 0
+}
+
+func formatDisplayName(name string) string {
+	name = strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return r < ' ' || r == 0x7f || r == ' '
+	}), " ")
+	if name == "" {
+		return ""
+	}
+
+	if !isASCII(name) {
+		return mime.QEncoding.Encode("utf-8", name)
+	}
+
+	plain := true
+	for i := 0; i < len(name) && plain; i++ {
+		plain = isPhraseChar(name[i])
+	}
+	if plain && !strings.HasPrefix(name, "=?") {
+		return name
+	}
+	return quoteLogin(name)
+}
+
+// String renders the mailbox for a header: `Alice <alice@example.io>`, quoting or
+// RFC 2047-encoding the display name when needed.
+func (m *MailboxObj) String() string {
+	name := formatDisplayName(m.name)
+	if name == "" {
+		return m.email.SafeHeaderValue()
+	}
+	return name + " <" + m.email.SafeHeaderValue() + ">"
+}
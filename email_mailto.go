@@ -0,0 +1,201 @@
+package puremail
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// // // // // // // // // //
+
+// MailtoObj is a parsed or to-be-generated RFC 6068 mailto: URI.
+type MailtoObj struct {
+	To      []*EmailObj
+	Cc      []*EmailObj
+	Bcc     []*EmailObj
+	Subject string
+	Body    string
+	Headers map[string]string // any other hfields, keys in lower case
+}
+
+const mailtoScheme = "mailto:"
+
+// mailtoAddrs splits the still percent-encoded list on ',' before decoding each address, so an
+// escaped comma inside a quoted local part stays part of it (RFC 6068 §2).
+func mailtoAddrs(dst []*EmailObj, s string) ([]*EmailObj, error) {
+	for _, addr := range strings.Split(s, ",") {
+		addr, err := url.PathUnescape(addr)
+		if err != nil {
+			return dst, ErrInvalidMailto
+		}
+		if addr = strings.TrimSpace(addr); addr == "" {
+			continue
+		}
+		obj, err := New(addr)
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, obj)
+	}
+	return dst, nil
+}
+
+// ParseMailto parses `mailto:a@x.io,b@y.io?cc=c@z.io&subject=Hi%20there&body=...`.
+// Values are percent-decoded; "+" stays a plus sign as RFC 6068 requires.
+func ParseMailto(uri string) (*MailtoObj, error) {
+	uri = strings.TrimSpace(uri)
+	if len(uri) < len(mailtoScheme) || !strings.EqualFold(uri[:len(mailtoScheme)], mailtoScheme) {
+		return nil, ErrInvalidMailto
+	}
+	uri = uri[len(mailtoScheme):]
+	if i := strings.IndexByte(uri, '#'); i >= 0 {
+		uri = uri[:i]
+	}
+
+	to, query, _ := strings.Cut(uri, "?")
+
+	var err error
+	m := new(MailtoObj)
+	if m.To, err = mailtoAddrs(m.To, to); err != nil {
+		return nil, err
+	}
+
+	if query == "" {
+		return m, nil
+	}
+	for _, field := range strings.Split(query, "&") {
+		if field == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, ErrInvalidMailto
+		}
+		if name, err = url.PathUnescape(name); err != nil {
+			return nil, ErrInvalidMailto
+		}
+
+		var list *[]*EmailObj
+		switch name = strings.ToLower(name); name {
+		case "to":
+			list = &m.To
+		case "cc":
+			list = &m.Cc
+		case "bcc":
+			list = &m.Bcc
+		}
+		if list != nil {
+			if *list, err = mailtoAddrs(*list, value); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if value, err = url.PathUnescape(value); err != nil {
+			return nil, ErrInvalidMailto
+		}
+		switch name {
+		case "subject":
+			m.Subject = value
+		case "body":
+			m.Body = value
+		default:
+			if m.Headers == nil {
+				m.Headers = make(map[string]string)
+			}
+			if _, dup := m.Headers[name]; !dup {
+				m.Headers[name] = value
+			}
+		}
+	}
+	return m, nil
+}
+
+//
+
+// isMailtoChar reports the qchar set of RFC 6068 minus ",", which separates addresses.
+func isMailtoChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("-._~!$'()*+;:@", c) >= 0
+}
+
+func mailtoEscape(b *strings.Builder, s string) {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; isMailtoChar(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&15])
+		}
+	}
+}
+
+func mailtoJoin(b *strings.Builder, list []*EmailObj) {
+	for i, obj := range list {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		mailtoEscape(b, obj.MailFull())
+	}
+}
+
+// mailtoHeader strips line breaks so a header hfield cannot smuggle extra headers.
+func mailtoHeader(s string) string {
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+}
+
+// mailtoBody turns every line break into CRLF, as RFC 6068 asks for the body hfield.
+func mailtoBody(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r", "\n"), "\n", "\r\n")
+}
+
+// String renders the URI with every recipient and hfield percent-encoded.
+func (m *MailtoObj) String() string {
+	var b strings.Builder
+	b.WriteString(mailtoScheme)
+	mailtoJoin(&b, m.To)
+
+	sep := byte('?')
+	field := func(name string) {
+		b.WriteByte(sep)
+		mailtoEscape(&b, name)
+		b.WriteByte('=')
+		sep = '&'
+	}
+
+	if len(m.Cc) > 0 {
+		field("cc")
+		mailtoJoin(&b, m.Cc)
+	}
+	if len(m.Bcc) > 0 {
+		field("bcc")
+		mailtoJoin(&b, m.Bcc)
+	}
+	if m.Subject != "" {
+		field("subject")
+		mailtoEscape(&b, mailtoHeader(m.Subject))
+	}
+	if m.Body != "" {
+		field("body")
+		mailtoEscape(&b, mailtoBody(m.Body))
+	}
+
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field(mailtoHeader(name))
+		mailtoEscape(&b, mailtoHeader(m.Headers[name]))
+	}
+	return b.String()
+}
+
+// Mailto returns a plain `mailto:` link for the address.
+func (obj *EmailObj) Mailto() string {
+	return (&MailtoObj{To: []*EmailObj{obj}}).String()
+}
@@ -15,7 +15,7 @@ func (obj *EmailObj) Domain() string {
 }
 
 func (obj *EmailObj) Mail() string {
-	return obj.login + "@" + obj.domain
+	return obj.LoginQuoted() + "@" + obj.domain
 }
 
 func (obj *EmailObj) MailFull() string {
@@ -25,7 +25,7 @@ func (obj *EmailObj) MailFull() string {
 
 	b := make([]byte, 0, obj.len)
 
-	b = append(b, obj.login...)
+	b = append(b, obj.LoginQuoted()...)
 
 	for _, p := range obj.prefixes {
 		b = append(b, p.char)
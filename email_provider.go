@@ -0,0 +1,87 @@
+package puremail
+
+import (
+	"strings"
+	"sync"
+)
+
+// // // // // // // // // //
+
+// ProviderRuleObj describes the local parts a mailbox provider hands out at sign-up.
+// Letters a-z and digits are always allowed; Chars lists the extra ones.
+type ProviderRuleObj struct {
+	MinLen, MaxLen int
+	Chars          string
+	FirstLetter    bool // must start with a letter
+}
+
+var providerRules = struct {
+	mu   sync.RWMutex
+	data map[string]ProviderRuleObj
+}{data: make(map[string]ProviderRuleObj)}
+
+func init() {
+	AddProviderRule(ProviderRuleObj{MinLen: 6, MaxLen: 30, Chars: "."}, "gmail.com", "googlemail.com")
+	AddProviderRule(ProviderRuleObj{MinLen: 1, MaxLen: 64, Chars: "._-", FirstLetter: true},
+		"outlook.com", "hotmail.com", "live.com", "msn.com")
+	AddProviderRule(ProviderRuleObj{MinLen: 4, MaxLen: 32, Chars: "._", FirstLetter: true}, "yahoo.com", "ymail.com")
+	AddProviderRule(ProviderRuleObj{MinLen: 3, MaxLen: 20, Chars: "._", FirstLetter: true}, "icloud.com", "me.com", "mac.com")
+	AddProviderRule(ProviderRuleObj{MinLen: 1, MaxLen: 30, Chars: ".-", FirstLetter: true},
+		"yandex.ru", "yandex.com", "ya.ru")
+}
+
+// AddProviderRule sets the rule for the given domains, replacing any previous one.
+func AddProviderRule(rule ProviderRuleObj, domains ...string) {
+	providerRules.mu.Lock()
+	defer providerRules.mu.Unlock()
+
+	for _, d := range domains {
+		if d = setKey(d); d != "" {
+			providerRules.data[d] = rule
+		}
+	}
+	PurgeCache()
+}
+
+// RemoveProviderRule drops the rules of the given domains.
+func RemoveProviderRule(domains ...string) {
+	providerRules.mu.Lock()
+	defer providerRules.mu.Unlock()
+
+	for _, d := range domains {
+		delete(providerRules.data, setKey(d))
+	}
+	PurgeCache()
+}
+
+// badIndex returns the offset of the first violation in login, or -1.
+func (r *ProviderRuleObj) badIndex(login string) int {
+	for i := 0; i < len(login); i++ {
+		c := login[i]
+		if 'a' <= c && c <= 'z' {
+			continue
+		}
+		if i == 0 && r.FirstLetter || !('0' <= c && c <= '9') && strings.IndexByte(r.Chars, c) < 0 {
+			return i
+		}
+	}
+
+	switch {
+	case r.MinLen > 0 && len(login) < r.MinLen:
+		return max(len(login)-1, 0)
+	case r.MaxLen > 0 && len(login) > r.MaxLen:
+		return r.MaxLen
+	}
+	return -1
+}
+
+func providerLoginBadIndex(login, domain string) int {
+	providerRules.mu.RLock()
+	rule, ok := providerRules.data[domain]
+	providerRules.mu.RUnlock()
+
+	if !ok {
+		return -1
+	}
+	return rule.badIndex(login)
+}
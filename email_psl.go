@@ -0,0 +1,152 @@
+package puremail
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/net/idna"
+)
+
+// // // // // // // // // //
+
+const (
+	pslRule byte = 1 << iota
+	pslWildcard
+	pslException
+)
+
+type pslObj struct {
+	rules map[string]byte
+}
+
+var (
+	psl     atomic.Pointer[pslObj]
+	pslOnce sync.Once
+)
+
+func parsePSL(r io.Reader) (*pslObj, error) {
+	p := &pslObj{rules: make(map[string]byte, 16_384)}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexAny(line, " \t"); i >= 0 {
+			line = line[:i]
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+
+		kind := pslRule
+		switch {
+		case strings.HasPrefix(line, "!"):
+			kind, line = pslException, line[1:]
+		case strings.HasPrefix(line, "*."):
+			kind, line = pslWildcard, line[2:]
+		}
+
+		rule, err := idna.ToASCII(strings.ToLower(line))
+		if err != nil || rule == "" {
+			continue
+		}
+		p.rules[rule] |= kind
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func pslCurrent() *pslObj {
+	pslOnce.Do(func() {
+		if psl.Load() != nil {
+			return
+		}
+
+		f, err := dataFS.Open("data/public_suffix_list.dat")
+		if err != nil {
+			panic("puremail: missing embedded public suffix list")
+		}
+		defer f.Close()
+
+		p, err := parsePSL(f)
+		if err != nil {
+			panic("puremail: broken embedded public suffix list")
+		}
+		psl.CompareAndSwap(nil, p)
+	})
+	return psl.Load()
+}
+
+// LoadPSL replaces the embedded list with a newer public_suffix_list.dat.
+func LoadPSL(r io.Reader) error {
+	p, err := parsePSL(r)
+	if err != nil {
+		return err
+	}
+	psl.Store(p)
+	PurgeCache()
+	return nil
+}
+
+//
+
+func (p *pslObj) publicSuffix(domain string) string {
+	suffix := domain[strings.LastIndexByte(domain, '.')+1:]
+
+	for i := len(domain); i > 0; {
+		i = strings.LastIndexByte(domain[:i], '.')
+		cand := domain[i+1:]
+		kind := p.rules[cand]
+
+		if kind&pslException != 0 {
+			return cand[strings.IndexByte(cand, '.')+1:]
+		}
+		if kind&pslRule != 0 {
+			suffix = cand
+		}
+		if kind&pslWildcard != 0 && i > 0 {
+			suffix = domain[strings.LastIndexByte(domain[:i], '.')+1:]
+		}
+		if i < 0 {
+			break
+		}
+	}
+	return suffix
+}
+
+func publicSuffix(domain string) string {
+	if domain == "" || isLiteralDomain(domain) {
+		return ""
+	}
+	return pslCurrent().publicSuffix(domain)
+}
+
+func registrableDomain(domain string) string {
+	suffix := publicSuffix(domain)
+	if suffix == "" || len(suffix) >= len(domain) {
+		return ""
+	}
+
+	rest := domain[:len(domain)-len(suffix)-1]
+	return domain[strings.LastIndexByte(rest, '.')+1:]
+}
+
+func isPublicSuffix(domain string) bool {
+	return publicSuffix(domain) == domain
+}
+
+//
+
+func (obj *EmailObj) PublicSuffix() string {
+	return publicSuffix(obj.domain)
+}
+
+// RegistrableDomain returns the public suffix plus one label ("example.co.uk"),
+// or an empty string when the domain is itself a public suffix.
+func (obj *EmailObj) RegistrableDomain() string {
+	return registrableDomain(obj.domain)
+}
@@ -0,0 +1,117 @@
+package puremail
+
+import (
+	"strings"
+)
+
+// // // // // // // // // //
+
+// isQText follows RFC 5321 qtextSMTP: printable ASCII and space, except '"' and '\'.
+func isQText(c byte) bool {
+	return c == ' ' || c == '!' || '#' <= c && c <= '[' || ']' <= c && c <= '~'
+}
+
+func quoteLogin(login string) string {
+	var b strings.Builder
+	b.Grow(len(login) + 4)
+
+	b.WriteByte('"')
+	for i := 0; i < len(login); i++ {
+		if c := login[i]; c == '"' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(login[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func unquoteLogin(s string) (string, bool) {
+	if len(s) < 3 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 1; i < len(s)-1; i++ {
+		c := s[i]
+		if c == '\\' {
+			i++
+			if i == len(s)-1 {
+				return "", false
+			}
+			c = s[i]
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), true
+}
+
+// parseQuotedLogin reads a leading quoted string and returns the unquoted login, its canonical
+// wire form (empty when a plain dot-atom without tag delimiters is enough) and the index right after the '@'.
+func parseQuotedLogin(s string, p *ConfigParseObj) (login, wire string, next int, err error) {
+	var buf [254]byte
+	bufLen := 0
+
+	i := 1
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+
+		switch {
+		case c == '"':
+			if i+1 >= len(s) || s[i+1] != '@' {
+				return "", "", 0, newParseError(s, ErrInvalidQuoted, ComponentLogin, i+1)
+			}
+			if bufLen == 0 {
+				return "", "", 0, newParseError(s, ErrInvalidLogin, ComponentLogin, i)
+			}
+
+			login = string(buf[:bufLen])
+			if !isASCII(login) {
+				if login, err = normalizeUTF8Quoted(login); err != nil {
+					return "", "", 0, newParseError(s, err, ComponentLogin, nonASCIIIndex(s))
+				}
+			}
+			if !isValidLogin(login) || strings.ContainsAny(login, "+=") {
+				wire = quoteLogin(login)
+			}
+			return login, wire, i + 2, nil
+
+		case c == '\\':
+			i++
+			if i >= len(s) || s[i] < ' ' || s[i] > '~' {
+				return "", "", 0, newParseError(s, ErrInvalidQuoted, ComponentLogin, i)
+			}
+			c = s[i]
+			if c >= 'A' && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+
+		case c >= 0x80 && p.EAI:
+
+		case !isQText(c):
+			return "", "", 0, newParseError(s, ErrInvalidQuoted, ComponentLogin, i)
+		}
+
+		buf[bufLen] = c
+		bufLen++
+	}
+	return "", "", 0, newParseError(s, ErrInvalidQuoted, ComponentLogin, len(s))
+}
+
+//
+
+// LoginQuoted returns the local part as it appears on the wire, quoted when needed.
+func (obj *EmailObj) LoginQuoted() string {
+	if obj.quoted != "" {
+		return obj.quoted
+	}
+	return obj.login
+}
+
+func (obj *EmailObj) IsQuoted() bool {
+	return obj.quoted != ""
+}
@@ -0,0 +1,32 @@
+package puremail
+
+import (
+	"io"
+	"strings"
+)
+
+// // // // // // // // // //
+
+var roleSet = newSetKeyObj("data/roles.txt", roleKey)
+
+func roleKey(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '-', '_':
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimRight(s, "0123456789")
+}
+
+func AddRole(names ...string)       { roleSet.add(names...) }
+func LoadRoles(r io.Reader) error   { return roleSet.load(r) }
+func isRoleLogin(login string) bool { return roleSet.has(roleKey(login)) }
+
+//
+
+func (obj *EmailObj) IsRole() bool {
+	return isRoleLogin(obj.login)
+}
@@ -0,0 +1,43 @@
+package puremail
+
+import (
+	"strings"
+	"unicode"
+)
+
+// // // // // // // // // //
+
+// isUnsafeRune covers C0/C1 controls, DEL and invisible format characters
+// (zero-width spaces, BOM, bidi overrides) that users paste along with addresses.
+func isUnsafeRune(r rune) bool {
+	return unicode.In(r, unicode.Cc, unicode.Cf)
+}
+
+// Sanitize prepares raw user input for New: it drops control and invisible format characters
+// and trims surrounding whitespace. It never makes an invalid address valid by guessing.
+func Sanitize(s string) string {
+	if strings.IndexFunc(s, isUnsafeRune) >= 0 {
+		s = strings.Map(func(r rune) rune {
+			if isUnsafeRune(r) {
+				return -1
+			}
+			return r
+		}, s)
+	}
+	return strings.TrimSpace(s)
+}
+
+// SafeHeaderValue renders the address for an outgoing header or SMTP envelope. Parsed addresses
+// never hold control characters; objects built by hand are scrubbed so CR/LF cannot leak.
+func (obj *EmailObj) SafeHeaderValue() string {
+	s := obj.MailFull()
+	if controlIndex(s) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
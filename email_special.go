@@ -0,0 +1,30 @@
+package puremail
+
+import "io"
+
+// // // // // // // // // //
+
+var specialUseSet = newSetObj("data/special_use.txt")
+
+func AddSpecialUse(domains ...string) {
+	specialUseSet.add(domains...)
+	PurgeCache()
+}
+
+func LoadSpecialUse(r io.Reader) error {
+	defer PurgeCache()
+	return specialUseSet.load(r)
+}
+
+func isSpecialUseDomain(domain string) bool {
+	_, ok := specialUseSet.matchSuffix(domain)
+	return ok
+}
+
+//
+
+// IsSpecialUse reports reserved names (.test, .invalid, .localhost, example.com, ...) that never
+// resolve on the public internet. HasMX answers them without a DNS query.
+func (obj *EmailObj) IsSpecialUse() bool {
+	return isSpecialUseDomain(obj.domain)
+}
@@ -0,0 +1,230 @@
+package puremail
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// // // // // // // // // //
+
+type weightedObj struct {
+	name   string
+	weight int
+}
+
+type weightedListObj struct {
+	mu        sync.RWMutex
+	items     []weightedObj
+	maxWeight int
+}
+
+func newWeightedListObj(file string) *weightedListObj {
+	l := new(weightedListObj)
+
+	f, err := dataFS.Open(file)
+	if err != nil {
+		panic("puremail: missing embedded " + file)
+	}
+	defer f.Close()
+
+	if err = l.load(f); err != nil {
+		panic("puremail: broken embedded " + file)
+	}
+	return l
+}
+
+func (l *weightedListObj) add(name string, weight int) {
+	name = setKey(name)
+	if name == "" {
+		return
+	}
+	if weight < 1 {
+		weight = 1
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if weight > l.maxWeight {
+		l.maxWeight = weight
+	}
+	for i := range l.items {
+		if l.items[i].name == name {
+			l.items[i].weight = weight
+			return
+		}
+	}
+	l.items = append(l.items, weightedObj{name: name, weight: weight})
+}
+
+func (l *weightedListObj) load(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		weight := 1
+		if len(fields) > 1 {
+			w, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return err
+			}
+			weight = w
+		}
+		l.add(fields[0], weight)
+	}
+	return sc.Err()
+}
+
+func (l *weightedListObj) has(name string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, it := range l.items {
+		if it.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// closest picks the entry with the smallest edit distance, preferring heavier entries on ties.
+func (l *weightedListObj) closest(s string, maxDist int) (best weightedObj, bestDist int, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	bestDist = maxDist + 1
+	for _, it := range l.items {
+		d := editDistance(s, it.name)
+		if d == 0 || d > maxDist {
+			continue
+		}
+		if d < bestDist || d == bestDist && it.weight > best.weight {
+			best, bestDist = it, d
+		}
+	}
+	return best, bestDist, bestDist <= maxDist
+}
+
+func (l *weightedListObj) confidence(it weightedObj, dist, size int) float64 {
+	l.mu.RLock()
+	maxWeight := l.maxWeight
+	l.mu.RUnlock()
+
+	base := 1 - float64(dist)/float64(size)
+	if base < 0 {
+		return 0
+	}
+	return base * (0.8 + 0.2*float64(it.weight)/float64(maxWeight))
+}
+
+// editDistance is the optimal string alignment distance: Levenshtein plus adjacent transpositions.
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+//
+
+var (
+	suggestDomains = newWeightedListObj("data/suggest_domains.txt")
+	suggestTLDs    = newWeightedListObj("data/suggest_tlds.txt")
+)
+
+func AddSuggestDomain(domain string, weight int) { suggestDomains.add(domain, weight) }
+func AddSuggestTLD(suffix string, weight int)    { suggestTLDs.add(suffix, weight) }
+func LoadSuggestDomains(r io.Reader) error       { return suggestDomains.load(r) }
+func LoadSuggestTLDs(r io.Reader) error          { return suggestTLDs.load(r) }
+
+func suggestMaxDist(s string) int {
+	if len(s) <= 7 {
+		return 1
+	}
+	return 2
+}
+
+// regionalDomain reports whether domain is the brand of candidate under another real suffix
+// (hotmail.de for hotmail.fr, gmx.at for gmx.de). A suffix with letters missing ("yahoo.co",
+// "yahoo.cm") is still treated as a typo.
+func regionalDomain(domain, candidate string) bool {
+	suffix, candSuffix := publicSuffix(domain), publicSuffix(candidate)
+	if !isKnownTLD(domain) || domain[:len(domain)-len(suffix)] != candidate[:len(candidate)-len(candSuffix)] {
+		return false
+	}
+
+	i := 0
+	for j := 0; i < len(suffix) && j < len(candSuffix); j++ {
+		if suffix[i] == candSuffix[j] {
+			i++
+		}
+	}
+	return i < len(suffix)
+}
+
+func suggestDomain(domain string) (string, float64) {
+	if domain == "" || isLiteralDomain(domain) || suggestDomains.has(domain) {
+		return "", 0
+	}
+
+	if it, dist, ok := suggestDomains.closest(domain, suggestMaxDist(domain)); ok {
+		if regionalDomain(domain, it.name) {
+			return "", 0
+		}
+		return it.name, suggestDomains.confidence(it, dist, max(len(domain), len(it.name)))
+	}
+
+	suffix := publicSuffix(domain)
+	if len(suffix) >= len(domain) || isKnownTLD(domain) || suggestTLDs.has(suffix) {
+		return "", 0
+	}
+
+	if it, dist, ok := suggestTLDs.closest(suffix, 1); ok {
+		return domain[:len(domain)-len(suffix)] + it.name, suggestTLDs.confidence(it, dist, max(len(suffix), len(it.name))+1)
+	}
+	return "", 0
+}
+
+// Suggest proposes a corrected address for a likely domain typo ("gmial.com" → "gmail.com")
+// with a confidence in 0..1. An empty string means there is nothing to suggest.
+func Suggest(obj *EmailObj) (string, float64) {
+	domain, confidence := suggestDomain(obj.domain)
+	if domain == "" {
+		return "", 0
+	}
+
+	fixed := *obj
+	fixed.domain = domain
+	fixed.len += len(domain) - len(obj.domain)
+	return fixed.MailFull(), confidence
+}
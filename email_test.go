@@ -3,9 +3,11 @@ package puremail
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"hash/crc32"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -109,6 +111,9 @@ func FuzzDecode(f *testing.F) {
 		if err == nil && obj == nil {
 			t.Fatalf("decode without error but turned nil")
 		}
+		if err == nil && controlIndex(obj.MailFull()) >= 0 {
+			t.Fatalf("decoded control characters: %q", obj.MailFull())
+		}
 	})
 }
 
@@ -121,6 +126,33 @@ func stubMxLookup(counter *int32) func(ctx context.Context, domain string) ([]*n
 	}
 }
 
+func TestMxCacheArenaDomain(t *testing.T) {
+	var calls int32
+
+	old := lookupMX
+	lookupMX = stubMxLookup(&calls)
+	defer func() { lookupMX = old }()
+
+	var obj EmailObj
+	arena, err := AppendParse(nil, &obj, []byte("bob@arena-mx.com"))
+	if err != nil {
+		t.Fatalf("AppendParse: %v", err)
+	}
+	if err = obj.HasMX(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range arena {
+		arena[i] = 'x'
+	}
+
+	if err = newObj("", "arena-mx.com").HasMX(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("MX cache key changed with the arena: %d lookups", got)
+	}
+}
+
 func TestMxCacheHit(t *testing.T) {
 	var calls int32
 
@@ -128,7 +160,7 @@ func TestMxCacheHit(t *testing.T) {
 	lookupMX = stubMxLookup(&calls)
 	defer func() { lookupMX = old }()
 
-	if err := newObj("", "example.com").HasMX(); err != nil {
+	if err := newObj("", "cachehit.com").HasMX(); err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	if got := atomic.LoadInt32(&calls); got != 1 {
@@ -164,21 +196,55 @@ func TestMxSingleFlight(t *testing.T) {
 	}
 }
 
+func TestMxErrors(t *testing.T) {
+	oldLookup := lookupMX
+	lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		return nil, &net.DNSError{Err: "no such host", Name: domain, IsNotFound: true}
+	}
+	defer func() { lookupMX = oldLookup }()
+
+	err := newObj("", "no-mx-errors.com").HasMX()
+	var de *net.DNSError
+	if !errors.Is(err, ErrNilMX) || ErrorCode(err) != CodeNilMX || !errors.As(err, &de) || de.Name != "no-mx-errors.com" {
+		t.Errorf("HasMX without records = %v", err)
+	}
+
+	if err = mxError("x.io", ErrToManyLookups); !errors.Is(err, ErrToManyLookups) || ErrorCode(err) != CodeToManyLookups {
+		t.Errorf("lookup limit error = %v", err)
+	}
+}
+
 func TestMxConcurrencyLimit(t *testing.T) {
+	release := make(chan struct{})
+	oldLookup := lookupMX
+	lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		<-release
+		return []*net.MX{{Host: "mx." + domain, Pref: 10}}, nil
+	}
+
 	start := make(chan struct{})
-	done := make(chan struct{})
+	done := make(chan struct{}, 3)
 
+	var wg sync.WaitGroup
 	for i := 0; i < 3; i++ {
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
 			<-start
-			newObj("", "example.com").HasMX()
+			newObj("", "concurrency-limit.com").HasMX()
 			done <- struct{}{}
 		}()
 	}
 	close(start)
 
 	time.Sleep(100 * time.Millisecond)
-	if n := len(done); n > 2 {
+	n := len(done)
+
+	close(release)
+	wg.Wait()
+	lookupMX = oldLookup
+
+	if n > 2 {
 		t.Fatalf("limit broken: %d done, want ≤2", n)
 	}
 }
@@ -268,7 +334,7 @@ func BenchmarkHasMXCached(b *testing.B) {
 	lookupMX = stubMxLookup(&calls)
 	defer func() { lookupMX = oldLookup }()
 
-	obj := newObj("", "example.com")
+	obj := newObj("", "bigcorp.com")
 
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -315,3 +381,279 @@ func BenchmarkHasMXParallel(b *testing.B) {
 
 	b.ReportMetric(float64(atomic.LoadInt32(&calls)), "dns_calls")
 }
+
+//
+
+func TestIsDisposable(t *testing.T) {
+	cases := map[string]bool{
+		"mailinator.com":        true,
+		"inbox.mailinator.com":  true,
+		"guerrillamail.de":      true,
+		"example.com":           false,
+		"notmailinator.com":     false,
+		"custom-throwaway.test": true,
+	}
+	AddDisposable("Custom-Throwaway.TEST.")
+
+	for domain, want := range cases {
+		if got := newObj("user", domain).IsDisposable(); got != want {
+			t.Errorf("IsDisposable(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+func TestIsDisposableMX(t *testing.T) {
+	oldLookup := lookupMX
+	lookupMX = func(ctx context.Context, domain string) ([]*net.MX, error) {
+		if domain == "alias-for-burner.com" {
+			return []*net.MX{{Host: "mx2.Mailinator.com.", Pref: 10}}, nil
+		}
+		return []*net.MX{{Host: "mx." + domain + ".", Pref: 10}}, nil
+	}
+	defer func() { lookupMX = oldLookup }()
+
+	AddDisposableMX("burner-infra.net")
+
+	cases := map[string]bool{
+		"alias-for-burner.com": true,
+		"mx.burner-infra.net":  true,
+		"regular-company.com":  false,
+	}
+	for domain, want := range cases {
+		got, err := newObj("user", domain).IsDisposableMX()
+		if err != nil {
+			t.Fatalf("IsDisposableMX(%q): unexpected error: %v", domain, err)
+		}
+		if got != want {
+			t.Errorf("IsDisposableMX(%q) = %v, want %v", domain, got, want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		obj  *EmailObj
+		want EmailClass
+	}{
+		{newObj("alice", "gmail.com"), ClassFree},
+		{newObj("info", "gmail.com"), ClassFree | ClassRole},
+		{newObj("alice", "mail.gmail.com"), ClassFree},
+		{newObj("alice", "gmail.com.bigcorp.com"), ClassCorporate},
+		{newObj("bob", "mailinator.com"), ClassDisposable},
+		{newObj("carol", "cs.stanford.edu"), ClassEducation},
+		{newObj("dan", "ox.ac.uk"), ClassEducation},
+		{newObj("eve", "cabinet.gov.ua"), ClassGovernment},
+		{newObj("frank", "bigcorp.com"), ClassCorporate},
+		{newObj("sales", "bigcorp.com"), ClassCorporate | ClassRole},
+	}
+
+	for _, tc := range cases {
+		if got := tc.obj.Classify(); got != tc.want {
+			t.Errorf("Classify(%s) = %s, want %s", tc.obj.Mail(), got, tc.want)
+		}
+	}
+}
+
+func TestClassifyExtend(t *testing.T) {
+	if err := AddClassDomains(ClassFree, "freebie.example"); err != nil {
+		t.Fatalf("AddClassDomains: %v", err)
+	}
+	if err := AddClassDomains(ClassRole, "x.example"); !errors.Is(err, ErrUnknownClass) {
+		t.Fatalf("want ErrUnknownClass, got %v", err)
+	}
+	AddClassifier(func(obj *EmailObj) EmailClass {
+		if obj.Domain() == "school.example" {
+			return ClassEducation
+		}
+		return 0
+	})
+
+	if got := newObj("a", "freebie.example").Classify(); got != ClassFree {
+		t.Errorf("custom free domain: got %s", got)
+	}
+	if got := newObj("a", "school.example").Classify(); got != ClassEducation {
+		t.Errorf("custom classifier: got %s", got)
+	}
+	old := classifiers
+	defer func() { classifiers = old }()
+	AddClassifier(func(obj *EmailObj) EmailClass {
+		if obj.Domain() == "nested.example" {
+			AddClassifier(func(*EmailObj) EmailClass { return 0 })
+		}
+		return 0
+	})
+	if got := newObj("a", "nested.example").Classify(); got != ClassCorporate {
+		t.Errorf("hook registering a hook: got %s", got)
+	}
+
+	if got := (ClassFree | ClassRole).String(); got != "free|role" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestIsRole(t *testing.T) {
+	cases := map[string]bool{
+		"admin":        true,
+		"no-reply":     true,
+		"no_reply":     true,
+		"noreply2":     true,
+		"post.master":  true,
+		"vertrieb":     true,
+		"pidtrymka":    true,
+		"підтримка":    true,
+		"alice":        false,
+		"infobot":      false,
+		"ops-oncall":   true,
+		"john.smith42": false,
+	}
+	AddRole("Ops-OnCall")
+
+	for login, want := range cases {
+		if got := newObj(login, "example.com").IsRole(); got != want {
+			t.Errorf("IsRole(%q) = %v, want %v", login, got, want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	cases := []struct {
+		obj  *EmailObj
+		want string
+	}{
+		{newObj("alice", "gmial.com"), "alice@gmail.com"},
+		{newObj("alice", "hotmial.com"), "alice@hotmail.com"},
+		{newObj("alice", "yahoo.co"), "alice@yahoo.com"},
+		{newObj("alice", "gmail.con"), "alice@gmail.com"},
+		{newObj("alice", "mycompany.con"), "alice@mycompany.com"},
+		{newObj("alice", "mycompany.cmo"), "alice@mycompany.com"},
+		{newObj("alice", "gmail.com"), ""},
+		{newObj("alice", "ymail.com"), ""},
+		{newObj("alice", "example.org"), ""},
+		{newObj("alice", "bigcorp.io"), ""},
+		{newObj("alice", "yahoo.cm"), "alice@yahoo.com"},
+		{newObj("alice", "hotmail.de"), ""},
+		{newObj("alice", "hotmail.be"), ""},
+		{newObj("alice", "gmx.at"), ""},
+		{newObj("alice", "gmx.ch"), ""},
+		{newObj("alice", "mail.de"), ""},
+		{newObj("alice", "yandex.kz"), ""},
+		{newObj("alice", "bbc.com"), ""},
+		{newObj("alice", "abc.com"), ""},
+		{newObj("alice", "web.com"), ""},
+		{newObj("alice", "gmial.com", EmailPrefixObj{char: '+', text: "dev"}), "alice+dev@gmail.com"},
+	}
+
+	for _, tc := range cases {
+		got, confidence := Suggest(tc.obj)
+		if got != tc.want {
+			t.Errorf("Suggest(%s) = %q, want %q", tc.obj.Mail(), got, tc.want)
+			continue
+		}
+		if tc.want == "" && confidence != 0 {
+			t.Errorf("Suggest(%s): confidence %v without suggestion", tc.obj.Mail(), confidence)
+		}
+		if tc.want != "" && (confidence < 0.5 || confidence > 1) {
+			t.Errorf("Suggest(%s): confidence %v out of range", tc.obj.Mail(), confidence)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"gmail.com", "gmail.com", 0},
+		{"gmial.com", "gmail.com", 1},
+		{"gmail.con", "gmail.com", 1},
+		{"yahoo.co", "yahoo.com", 1},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+	}
+	for _, tc := range cases {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestSpecialUse(t *testing.T) {
+	var calls int32
+	oldLookup := lookupMX
+	lookupMX = stubMxLookup(&calls)
+	defer func() { lookupMX = oldLookup }()
+
+	for _, domain := range []string{"example.com", "foo.localhost", "printer.local", "site.test", "x.invalid", "abc.onion", "router.home.arpa"} {
+		obj := newObj("user", domain)
+		if !obj.IsSpecialUse() {
+			t.Errorf("IsSpecialUse(%q) = false", domain)
+		}
+		if err := obj.HasMX(); !errors.Is(err, ErrSpecialUse) || ErrorCode(err) != CodeSpecialUse ||
+			!strings.Contains(err.Error(), domain) {
+			t.Errorf("HasMX(%q) = %v", domain, err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Errorf("special-use names must not hit DNS, got %d lookups", got)
+	}
+
+	if newObj("user", "examples.com").IsSpecialUse() {
+		t.Errorf("examples.com is not special-use")
+	}
+}
+
+func TestHomograph(t *testing.T) {
+	cases := []struct {
+		obj  *EmailObj
+		risk bool
+	}{
+		{newObj("alice", "apple.com"), false},
+		{newObj("alice", domainMust("аpple.com")), true},
+		{newObj("alice", domainMust("аррӏе.com")), true},
+		{newObj("пошта", domainMust("приклад.укр")), false},
+		{newObj("аlice", "example.com"), true},
+		{newObj("user", domainMust("例え.テスト")), false},
+		{newObj("user", domainMust("bücher.de")), false},
+	}
+
+	for _, tc := range cases {
+		if got := tc.obj.HomographRisk(); got != tc.risk {
+			t.Errorf("HomographRisk(%s / %s) = %v, want %v", tc.obj.Login(), tc.obj.DomainUnicode(), got, tc.risk)
+		}
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	genuine := newObj("paypal", "apple.com")
+	spoofs := []*EmailObj{
+		newObj("раураl", domainMust("аррӏе.com")),
+		newObj("paypa1", domainMust("appӏe.com")),
+		newObj("ｐａｙｐａｌ", "apple.com"),
+	}
+
+	for _, spoof := range spoofs {
+		if spoof.Skeleton() != genuine.Skeleton() {
+			t.Errorf("Skeleton(%s@%s) = %q, want %q", spoof.Login(), spoof.DomainUnicode(), spoof.Skeleton(), genuine.Skeleton())
+		}
+	}
+	if newObj("paypal", "apple.org").Skeleton() == genuine.Skeleton() {
+		t.Errorf("different domains must not share a skeleton")
+	}
+}
+
+func TestLoadConfusables(t *testing.T) {
+	if err := LoadConfusables(strings.NewReader("2C9F ;\t006F ;\tMA\t# COPTIC SMALL LETTER O\n")); err != nil {
+		t.Fatalf("LoadConfusables: %v", err)
+	}
+	if got := skeleton("gⲟ"); got != "go" {
+		t.Errorf("skeleton = %q", got)
+	}
+}
+
+func domainMust(unicode string) string {
+	ascii, ok := domainToASCII(unicode)
+	if !ok {
+		panic("bad test domain " + unicode)
+	}
+	return ascii
+}
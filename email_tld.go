@@ -0,0 +1,20 @@
+package puremail
+
+import (
+	"io"
+	"strings"
+)
+
+// // // // // // // // // //
+
+var tldSet = newSetObj("data/tlds.txt")
+
+// LoadTLD replaces the embedded list with a newer IANA tlds-alpha-by-domain.txt.
+func LoadTLD(r io.Reader) error {
+	defer PurgeCache()
+	return tldSet.replace(r)
+}
+
+func isKnownTLD(domain string) bool {
+	return tldSet.has(domain[strings.LastIndexByte(domain, '.')+1:])
+}
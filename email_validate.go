@@ -0,0 +1,184 @@
+package puremail
+
+import (
+	"errors"
+	"strings"
+)
+
+// // // // // // // // // //
+
+type Severity uint8
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+var severityNames = [...]string{"info", "warning", "error"}
+
+func (s Severity) String() string {
+	if int(s) < len(severityNames) {
+		return severityNames[s]
+	}
+	return "unknown"
+}
+
+// FindingObj is one problem found by Validate. Err works with errors.Is, ErrorCode and Message.
+type FindingObj struct {
+	Severity  Severity
+	Code      string
+	Err       error
+	Component ParseComponent
+	Offset    int    // byte offset in the input
+	Hint      string // suggested domain for CodeTypo
+}
+
+type ReportObj struct {
+	Email    *EmailObj // nil when any finding is an error
+	Findings []FindingObj
+}
+
+func (r *ReportObj) Valid() bool { return r.Worst() < SeverityError }
+
+// Worst returns the highest severity, SeverityInfo for an empty report.
+func (r *ReportObj) Worst() (worst Severity) {
+	for _, f := range r.Findings {
+		worst = max(worst, f.Severity)
+	}
+	return
+}
+
+func (r *ReportObj) Has(code string) bool {
+	for _, f := range r.Findings {
+		if f.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ReportObj) add(sev Severity, err error, comp ParseComponent, offset int) *FindingObj {
+	r.Findings = append(r.Findings, FindingObj{Severity: sev, Code: ErrorCode(err), Err: err, Component: comp, Offset: offset})
+	return &r.Findings[len(r.Findings)-1]
+}
+
+// addParse records a parse failure; shift maps offsets of a partial input back onto the original.
+// The offset of ErrLenMax is the limit itself and is never shifted.
+func (r *ReportObj) addParse(err error, shift int) {
+	if err == nil {
+		return
+	}
+
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		r.add(SeverityError, err, ComponentInput, 0)
+		return
+	}
+
+	shifted := *pe
+	if !errors.Is(pe, ErrLenMax) {
+		shifted.Offset += shift
+	}
+	if r.Has(shifted.Code) && shifted.Component == ComponentInput {
+		return
+	}
+	r.add(SeverityError, &shifted, shifted.Component, shifted.Offset)
+}
+
+//
+
+func policySeverity(reject bool) Severity {
+	if reject {
+		return SeverityError
+	}
+	return SeverityWarning
+}
+
+// Validate runs every check instead of stopping at the first one: the local part and the domain
+// are parsed independently, then the domain policies, disposable and role lists, homograph and
+// typo checks are applied. Policies enabled in ConfigParseObj are errors, the rest warnings.
+// No DNS lookups are made; call HasMX on Report.Email for that.
+func Validate(s string) *ReportObj {
+	r := new(ReportObj)
+
+	p := parseConf()
+	base := *p
+	base.StrictTLD, base.RejectPublicSuffix, base.RejectSpecialUse = false, false, false
+	base.RejectPrivateIP, base.Providers = false, false
+
+	at := strings.LastIndexByte(s, '@')
+	if at < 0 {
+		_, err := parseWith(s, false, &base)
+		r.addParse(err, 0)
+		return r
+	}
+
+	loginObj, loginErr := parseWith(s[:at]+"@a.io", false, &base)
+	domainObj, domainErr := parseWith("a@"+s[at+1:], false, &base)
+	r.addParse(loginErr, 0)
+	r.addParse(domainErr, at-1)
+
+	if loginErr == nil && domainErr == nil {
+		obj, err := parseWith(s, false, &base)
+		r.addParse(err, 0)
+		r.Email = obj
+	}
+
+	var probe EmailObj
+	if loginObj != nil {
+		probe.login = loginObj.login
+	}
+	if domainObj != nil {
+		probe.domain, probe.ip = domainObj.domain, domainObj.ip
+	}
+	r.policies(s, &probe, p, at)
+
+	if !r.Valid() {
+		r.Email = nil
+	}
+	return r
+}
+
+func (r *ReportObj) policies(s string, obj *EmailObj, p *ConfigParseObj, at int) {
+	domainAt := at + 1
+
+	if obj.domain != "" {
+		if obj.ip.IsValid() {
+			if isPrivateIP(obj.ip) {
+				r.add(policySeverity(p.RejectPrivateIP), ErrPrivateIP, ComponentDomain, domainAt)
+			}
+		} else {
+			if !isKnownTLD(obj.domain) {
+				r.add(policySeverity(p.StrictTLD), ErrUnknownTLD, ComponentLabel, domainAt)
+			}
+			if isPublicSuffix(obj.domain) {
+				r.add(policySeverity(p.RejectPublicSuffix), ErrPublicSuffix, ComponentDomain, domainAt)
+			}
+			if isSpecialUseDomain(obj.domain) {
+				r.add(policySeverity(p.RejectSpecialUse), ErrSpecialUse, ComponentDomain, domainAt)
+			}
+			if isDisposableDomain(obj.domain) {
+				r.add(SeverityWarning, ErrDisposable, ComponentDomain, domainAt)
+			}
+			if hint, _ := suggestDomain(obj.domain); hint != "" {
+				r.add(SeverityWarning, ErrTypo, ComponentDomain, domainAt).Hint = hint
+			}
+		}
+	}
+
+	if obj.login == "" {
+		return
+	}
+	if obj.domain != "" {
+		if bad := providerLoginBadIndex(obj.login, obj.domain); bad >= 0 {
+			r.add(policySeverity(p.Providers), ErrProviderLogin, ComponentLogin, loginOffset(s, bad))
+		}
+	}
+	if obj.HomographRisk() {
+		r.add(SeverityWarning, ErrHomograph, ComponentInput, 0)
+	}
+	if isRoleLogin(obj.login) {
+		r.add(SeverityInfo, ErrRoleLogin, ComponentLogin, 0)
+	}
+}
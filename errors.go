@@ -6,13 +6,22 @@ import "errors"
 
 var (
 	ErrLenMax             = errors.New("too many characters")
-	ErrManyA              = errors.New("to many @")
+	ErrLoginLenMax        = errors.New("email login is too long")
+	ErrManyA              = errors.New("too many @")
 	ErrInvalidLogin       = errors.New("invalid email login")
 	ErrInvalidLoginChars  = errors.New("invalid email login characters")
+	ErrInvalidQuoted      = errors.New("invalid quoted email login")
 	ErrInvalidDomain      = errors.New("invalid email domain")
 	ErrInvalidDomainChars = errors.New("invalid email domain characters")
 	ErrEndToTag           = errors.New("end to tag")
 	ErrEndToEOF           = errors.New("end to EOF")
+	ErrInvalidLiteral     = errors.New("invalid email address literal")
+	ErrPrivateIP          = errors.New("email address literal is a private or loopback IP")
+	ErrUnknownTLD         = errors.New("unknown top-level domain")
+	ErrPublicSuffix       = errors.New("email domain is a public suffix")
+	ErrSpecialUse         = errors.New("email domain is reserved for special use")
+	ErrProviderLogin      = errors.New("email login breaks the provider rules")
+	ErrControlChars       = errors.New("control characters are not allowed")
 	ErrPanic              = errors.New("catch panic")
 
 	ErrTooShort  = errors.New("payload is too short")
@@ -21,4 +30,14 @@ var (
 
 	ErrNilMX         = errors.New("no MX records found")
 	ErrToManyLookups = errors.New("too many lookups")
+
+	ErrUnknownClass = errors.New("class has no domain list")
+
+	ErrDisposable = errors.New("email domain is disposable")
+	ErrRoleLogin  = errors.New("email login is a role account")
+	ErrHomograph  = errors.New("email address imitates another script")
+	ErrTypo       = errors.New("email domain looks like a typo")
+
+	ErrInvalidMailbox = errors.New("invalid mailbox syntax")
+	ErrInvalidMailto  = errors.New("invalid mailto URI")
 )
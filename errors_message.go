@@ -0,0 +1,178 @@
+package puremail
+
+import (
+	"strings"
+	"sync"
+)
+
+// // // // // // // // // //
+
+// CodeInvalid is the catalogue key used for errors without a more specific code.
+const CodeInvalid = "invalid"
+
+var (
+	messagesMu sync.RWMutex
+	messages   = map[string]map[string]string{
+		"en": {
+			CodeInvalid:            "The email address is not valid.",
+			CodeLenMax:             "The email address is too long.",
+			CodeLoginLenMax:        "The part before the @ sign is too long.",
+			CodeManyA:              "The email address must contain exactly one @ sign.",
+			CodeInvalidLogin:       "Enter the part of the address before the @ sign.",
+			CodeInvalidLoginChars:  "The part before the @ sign contains characters that are not allowed.",
+			CodeInvalidQuoted:      "The quoted part before the @ sign is not valid.",
+			CodeInvalidDomain:      "Enter the domain after the @ sign.",
+			CodeInvalidDomainChars: "The domain after the @ sign contains characters that are not allowed.",
+			CodeInvalidLiteral:     "The IP address after the @ sign is not valid.",
+			CodePrivateIP:          "Private or local IP addresses are not allowed.",
+			CodeUnknownTLD:         "The domain ending is not a known top-level domain.",
+			CodePublicSuffix:       "Enter a full domain, not just its ending.",
+			CodeSpecialUse:         "This domain is reserved and cannot receive email.",
+			CodeProviderLogin:      "This provider does not allow such a name before the @ sign.",
+			CodeEndToTag:           "The address ends unexpectedly; add the @ sign and a domain.",
+			CodeEndToEOF:           "The email address must contain an @ sign.",
+			CodeControlChars:       "The email address contains line breaks or other invisible control characters.",
+			CodePanic:              "The email address could not be processed.",
+			CodeTooShort:           "The stored email address is damaged.",
+			CodeCRC:                "The stored email address is damaged.",
+			CodeMalformed:          "The stored email address is damaged.",
+			CodeNilMX:              "This domain cannot receive email.",
+			CodeToManyLookups:      "The domain could not be checked right now. Please try again.",
+			CodeUnknownClass:       "Domains cannot be added to this category.",
+			CodeDisposable:         "Addresses from temporary mailbox services are not accepted.",
+			CodeRoleLogin:          "This looks like a shared role address rather than a personal one.",
+			CodeHomograph:          "The address contains characters that imitate other letters.",
+			CodeTypo:               "The domain looks misspelled.",
+			CodeInvalidMailbox:     "The name and address are not written correctly.",
+			CodeInvalidMailto:      "The mailto link is not valid.",
+		},
+		"uk": {
+			CodeInvalid:            "Адреса електронної пошти некоректна.",
+			CodeLenMax:             "Адреса електронної пошти задовга.",
+			CodeLoginLenMax:        "Частина адреси перед знаком @ задовга.",
+			CodeManyA:              "Адреса електронної пошти має містити рівно один знак @.",
+			CodeInvalidLogin:       "Введіть частину адреси перед знаком @.",
+			CodeInvalidLoginChars:  "Частина адреси перед знаком @ містить недопустимі символи.",
+			CodeInvalidQuoted:      "Частина адреси в лапках перед знаком @ некоректна.",
+			CodeInvalidDomain:      "Введіть домен після знака @.",
+			CodeInvalidDomainChars: "Домен після знака @ містить недопустимі символи.",
+			CodeInvalidLiteral:     "IP-адреса після знака @ некоректна.",
+			CodePrivateIP:          "Приватні та локальні IP-адреси не дозволені.",
+			CodeUnknownTLD:         "Закінчення домену не є відомим доменом верхнього рівня.",
+			CodePublicSuffix:       "Введіть повний домен, а не лише його закінчення.",
+			CodeSpecialUse:         "Цей домен зарезервований і не може отримувати пошту.",
+			CodeProviderLogin:      "Цей поштовий сервіс не дозволяє таке ім'я перед знаком @.",
+			CodeEndToTag:           "Адреса несподівано обривається; додайте знак @ і домен.",
+			CodeEndToEOF:           "Адреса електронної пошти має містити знак @.",
+			CodeControlChars:       "Адреса містить розриви рядків або інші невидимі керівні символи.",
+			CodePanic:              "Не вдалося обробити адресу електронної пошти.",
+			CodeTooShort:           "Збережена адреса електронної пошти пошкоджена.",
+			CodeCRC:                "Збережена адреса електронної пошти пошкоджена.",
+			CodeMalformed:          "Збережена адреса електронної пошти пошкоджена.",
+			CodeNilMX:              "Цей домен не може отримувати пошту.",
+			CodeToManyLookups:      "Зараз не вдалося перевірити домен. Спробуйте ще раз.",
+			CodeUnknownClass:       "До цієї категорії не можна додавати домени.",
+			CodeDisposable:         "Адреси тимчасових поштових сервісів не приймаються.",
+			CodeRoleLogin:          "Схоже, це спільна службова адреса, а не особиста.",
+			CodeHomograph:          "Адреса містить символи, що імітують інші літери.",
+			CodeTypo:               "Схоже, у домені помилка.",
+			CodeInvalidMailbox:     "Ім'я та адресу записано неправильно.",
+			CodeInvalidMailto:      "Посилання mailto некоректне.",
+		},
+		"ru": {
+			CodeInvalid:            "Адрес электронной почты некорректен.",
+			CodeLenMax:             "Адрес электронной почты слишком длинный.",
+			CodeLoginLenMax:        "Часть адреса перед знаком @ слишком длинная.",
+			CodeManyA:              "Адрес электронной почты должен содержать ровно один знак @.",
+			CodeInvalidLogin:       "Введите часть адреса перед знаком @.",
+			CodeInvalidLoginChars:  "Часть адреса перед знаком @ содержит недопустимые символы.",
+			CodeInvalidQuoted:      "Часть адреса в кавычках перед знаком @ некорректна.",
+			CodeInvalidDomain:      "Введите домен после знака @.",
+			CodeInvalidDomainChars: "Домен после знака @ содержит недопустимые символы.",
+			CodeInvalidLiteral:     "IP-адрес после знака @ некорректен.",
+			CodePrivateIP:          "Частные и локальные IP-адреса не допускаются.",
+			CodeUnknownTLD:         "Окончание домена не является известным доменом верхнего уровня.",
+			CodePublicSuffix:       "Введите полный домен, а не только его окончание.",
+			CodeSpecialUse:         "Этот домен зарезервирован и не может получать почту.",
+			CodeProviderLogin:      "Этот почтовый сервис не допускает такое имя перед знаком @.",
+			CodeEndToTag:           "Адрес неожиданно обрывается; добавьте знак @ и домен.",
+			CodeEndToEOF:           "Адрес электронной почты должен содержать знак @.",
+			CodeControlChars:       "Адрес содержит переносы строк или другие невидимые управляющие символы.",
+			CodePanic:              "Не удалось обработать адрес электронной почты.",
+			CodeTooShort:           "Сохранённый адрес электронной почты повреждён.",
+			CodeCRC:                "Сохранённый адрес электронной почты повреждён.",
+			CodeMalformed:          "Сохранённый адрес электронной почты повреждён.",
+			CodeNilMX:              "Этот домен не может получать почту.",
+			CodeToManyLookups:      "Сейчас не удалось проверить домен. Попробуйте ещё раз.",
+			CodeUnknownClass:       "В эту категорию нельзя добавлять домены.",
+			CodeDisposable:         "Адреса временных почтовых сервисов не принимаются.",
+			CodeRoleLogin:          "Похоже, это общий служебный адрес, а не личный.",
+			CodeHomograph:          "Адрес содержит символы, имитирующие другие буквы.",
+			CodeTypo:               "Похоже, в домене опечатка.",
+			CodeInvalidMailbox:     "Имя и адрес записаны неправильно.",
+			CodeInvalidMailto:      "Ссылка mailto некорректна.",
+		},
+	}
+)
+
+func langKey(lang string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(lang)), "_", "-")
+}
+
+// RegisterLanguage adds or extends a catalogue; keys are error codes (Code* constants, CodeInvalid).
+func RegisterLanguage(lang string, catalogue map[string]string) {
+	lang = langKey(lang)
+
+	messagesMu.Lock()
+	defer messagesMu.Unlock()
+
+	dst := messages[lang]
+	if dst == nil {
+		dst = make(map[string]string, len(catalogue))
+		messages[lang] = dst
+	}
+	for code, msg := range catalogue {
+		dst[code] = msg
+	}
+}
+
+func lookupMessage(lang, code string) (string, bool) {
+	for {
+		if msg, ok := messages[lang][code]; ok {
+			return msg, true
+		}
+
+		i := strings.LastIndexByte(lang, '-')
+		if i < 0 {
+			return "", false
+		}
+		lang = lang[:i]
+	}
+}
+
+// Message renders err for end users in lang ("uk", "ru-RU", ...), falling back to English.
+func Message(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+
+	code := ErrorCode(err)
+	if code == "" {
+		code = CodeInvalid
+	}
+	lang = langKey(lang)
+
+	messagesMu.RLock()
+	defer messagesMu.RUnlock()
+
+	if msg, ok := lookupMessage(lang, code); ok {
+		return msg
+	}
+	if msg, ok := lookupMessage(lang, CodeInvalid); ok {
+		return msg
+	}
+	if msg, ok := lookupMessage("en", code); ok {
+		return msg
+	}
+	return messages["en"][CodeInvalid]
+}
@@ -0,0 +1,168 @@
+package puremail
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// // // // // // // // // //
+
+type ParseComponent uint8
+
+const (
+	ComponentInput ParseComponent = iota
+	ComponentLogin
+	ComponentTag
+	ComponentDomain
+	ComponentLabel
+)
+
+var componentNames = [...]string{"input", "login", "tag", "domain", "label"}
+
+func (c ParseComponent) String() string {
+	if int(c) < len(componentNames) {
+		return componentNames[c]
+	}
+	return "unknown"
+}
+
+//
+
+const (
+	CodeLenMax             = "len_max"
+	CodeLoginLenMax        = "login_len_max"
+	CodeManyA              = "many_at"
+	CodeInvalidLogin       = "login_empty"
+	CodeInvalidLoginChars  = "login_chars"
+	CodeInvalidQuoted      = "login_quoted"
+	CodeInvalidDomain      = "domain_empty"
+	CodeInvalidDomainChars = "domain_chars"
+	CodeInvalidLiteral     = "domain_literal"
+	CodePrivateIP          = "domain_private_ip"
+	CodeUnknownTLD         = "domain_unknown_tld"
+	CodePublicSuffix       = "domain_public_suffix"
+	CodeSpecialUse         = "domain_special_use"
+	CodeProviderLogin      = "login_provider"
+	CodeEndToTag           = "tag_unterminated"
+	CodeEndToEOF           = "missing_at"
+	CodeControlChars       = "control_chars"
+	CodePanic              = "panic"
+
+	CodeTooShort  = "payload_short"
+	CodeCRC       = "payload_crc"
+	CodeMalformed = "payload_malformed"
+
+	CodeNilMX         = "mx_none"
+	CodeToManyLookups = "mx_busy"
+
+	CodeUnknownClass = "class_unknown"
+
+	CodeDisposable = "domain_disposable"
+	CodeRoleLogin  = "login_role"
+	CodeHomograph  = "homograph"
+	CodeTypo       = "domain_typo"
+
+	CodeInvalidMailbox = "mailbox_syntax"
+	CodeInvalidMailto  = "mailto_syntax"
+)
+
+var errCodes = [...]struct {
+	err  error
+	code string
+}{
+	{ErrLenMax, CodeLenMax},
+	{ErrLoginLenMax, CodeLoginLenMax},
+	{ErrManyA, CodeManyA},
+	{ErrInvalidLogin, CodeInvalidLogin},
+	{ErrInvalidLoginChars, CodeInvalidLoginChars},
+	{ErrInvalidQuoted, CodeInvalidQuoted},
+	{ErrInvalidDomain, CodeInvalidDomain},
+	{ErrInvalidDomainChars, CodeInvalidDomainChars},
+	{ErrInvalidLiteral, CodeInvalidLiteral},
+	{ErrPrivateIP, CodePrivateIP},
+	{ErrUnknownTLD, CodeUnknownTLD},
+	{ErrPublicSuffix, CodePublicSuffix},
+	{ErrSpecialUse, CodeSpecialUse},
+	{ErrProviderLogin, CodeProviderLogin},
+	{ErrEndToTag, CodeEndToTag},
+	{ErrEndToEOF, CodeEndToEOF},
+	{ErrControlChars, CodeControlChars},
+	{ErrPanic, CodePanic},
+
+	{ErrTooShort, CodeTooShort},
+	{ErrCRC, CodeCRC},
+	{ErrMalformed, CodeMalformed},
+
+	{ErrNilMX, CodeNilMX},
+	{ErrToManyLookups, CodeToManyLookups},
+
+	{ErrUnknownClass, CodeUnknownClass},
+
+	{ErrDisposable, CodeDisposable},
+	{ErrRoleLogin, CodeRoleLogin},
+	{ErrHomograph, CodeHomograph},
+	{ErrTypo, CodeTypo},
+
+	{ErrInvalidMailbox, CodeInvalidMailbox},
+	{ErrInvalidMailto, CodeInvalidMailto},
+}
+
+// ErrorCode returns the stable code of any error produced by the package, or "" for foreign errors.
+func ErrorCode(err error) string {
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		return pe.Code
+	}
+
+	for _, ec := range errCodes {
+		if errors.Is(err, ec.err) {
+			return ec.code
+		}
+	}
+	return ""
+}
+
+//
+
+// ParseError describes where parsing stopped. It wraps one of the Err* sentinels.
+type ParseError struct {
+	Err       error
+	Code      string
+	Component ParseComponent
+	Offset    int  // byte offset in the input
+	Char      rune // offending character, 0 at end of input
+}
+
+func newParseError(s string, err error, comp ParseComponent, offset int) *ParseError {
+	e := &ParseError{Err: err, Component: comp, Offset: offset}
+	for _, ec := range errCodes {
+		if ec.err == err {
+			e.Code = ec.code
+			break
+		}
+	}
+
+	if offset >= 0 && offset < len(s) {
+		e.Char, _ = utf8.DecodeRuneInString(s[offset:])
+	}
+	return e
+}
+
+func (e *ParseError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	if e.Char != 0 {
+		b.WriteString(" ")
+		b.WriteString(strconv.QuoteRune(e.Char))
+	}
+	b.WriteString(" at offset ")
+	b.WriteString(strconv.Itoa(e.Offset))
+	b.WriteString(" (")
+	b.WriteString(e.Component.String())
+	b.WriteString(")")
+	return b.String()
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
@@ -2,6 +2,8 @@ package puremail
 
 import (
 	"errors"
+	"strings"
+	"unicode/utf8"
 )
 
 // // // // // // // // // //
@@ -22,16 +24,13 @@ func isValidLabel(label string) bool {
 	}
 
 	if len(label) >= 5 && label[:4] == "xn--" {
-		if len(label) > 63 {
-			return false
-		}
 		for i := 4; i < len(label); i++ {
 			c := label[i]
 			if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
 				return false
 			}
 		}
-		return true
+		return isValidALabel(label)
 	}
 
 	first, last := label[0], label[len(label)-1]
@@ -99,6 +98,138 @@ func isValidDomain(s string) bool {
 	return true
 }
 
+func loginFrom(b []byte, p *ConfigParseObj, in internObj) (string, error) {
+	login := in.string(b)
+	if isValidLogin(login) || p.HTML5 && isHTML5Login(login) {
+		return login, nil
+	}
+	if !p.EAI || p.HTML5 || isASCII(login) {
+		return "", ErrInvalidLoginChars
+	}
+	return normalizeUTF8Login(login)
+}
+
+// isHTML5Login is the local part of the HTML5 input[type=email] grammar: atext and dots in any order.
+func isHTML5Login(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '.' && !isLoginChar(s[i]) {
+			return false
+		}
+	}
+	return len(s) > 0
+}
+
+// loginBadIndex returns the offset of the first character that breaks the dot-atom rules.
+func loginBadIndex(s string, p *ConfigParseObj) int {
+	if p.HTML5 {
+		for i := 0; i < len(s); i++ {
+			if s[i] != '.' && !isLoginChar(s[i]) {
+				return i
+			}
+		}
+		return len(s) - 1
+	}
+
+	eai := p.EAI
+	segLen := 0
+	for i, r := range s {
+		if r == '.' {
+			if segLen == 0 {
+				return i
+			}
+			segLen = 0
+			continue
+		}
+		if r < utf8.RuneSelf && !isLoginChar(byte(r)) || r >= utf8.RuneSelf && (!eai || !isUTF8LoginRune(r)) {
+			return i
+		}
+		segLen++
+	}
+	return len(s) - 1
+}
+
+// loginOffset maps index n of the parsed login onto s, stepping over the opening quote and the
+// backslash escapes of a quoted local part.
+func loginOffset(s string, n int) int {
+	if len(s) == 0 || s[0] != '"' {
+		return n
+	}
+
+	i := 1
+	for ; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		}
+		if n == 0 {
+			break
+		}
+		n--
+	}
+	return i
+}
+
+// tagBadIndex returns the offset of the first character a tag may not carry, or -1.
+// Tags are part of the local part, so the login alphabet applies.
+func tagBadIndex(b []byte, eai bool) int {
+	for i := 0; i < len(b); {
+		if c := b[i]; c < utf8.RuneSelf {
+			if c != '.' && !isLoginChar(c) {
+				return i
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b[i:])
+		if !eai || !isUTF8LoginRune(r) {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+// controlIndex returns the offset of the first ASCII control character (CR, LF, NUL, DEL…), or -1.
+func controlIndex(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] < ' ' || s[i] == 0x7f {
+			return i
+		}
+	}
+	return -1
+}
+
+// domainBadIndex returns the offset of the first bad character, or the start of a label
+// that is wrong as a whole (length, hyphens, punycode).
+func domainBadIndex(s string) (int, ParseComponent) {
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] != '.' {
+			continue
+		}
+
+		if label := s[start:i]; !isValidLabel(label) {
+			for j := 0; j < len(label); j++ {
+				if c := label[j]; !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+					return start + j, ComponentDomain
+				}
+			}
+			return start, ComponentLabel
+		}
+		start = i + 1
+	}
+	return 0, ComponentDomain
+}
+
+func nonASCIIIndex(s string) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return i
+		}
+	}
+	return 0
+}
+
 func parseRecover(err *error) {
 	if r := recover(); r != nil {
 		*err = errors.Join(ErrPanic, *err)
@@ -107,21 +238,52 @@ func parseRecover(err *error) {
 
 // //
 
-func parse(s string, isShot bool) (obj *EmailObj, err error) {
-	if len(s) > 254 {
-		return nil, ErrLenMax
+func parse(s string, isShot bool) (*EmailObj, error) {
+	return parseWith(s, isShot, parseConf())
+}
+
+func parseWith(s string, isShot bool, p *ConfigParseObj) (*EmailObj, error) {
+	obj := new(EmailObj)
+	if err := parseInto(obj, s, isShot, p, internObj{}); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// parseInto fills obj, reusing its prefixes slice; in decides where the strings are stored.
+func parseInto(obj *EmailObj, s string, isShot bool, p *ConfigParseObj, in internObj) (err error) {
+	if lenMax := p.lenMax(); len(s) > lenMax {
+		return newParseError(s, ErrLenMax, ComponentInput, lenMax)
+	}
+
+	if i := controlIndex(s); i >= 0 {
+		return newParseError(s, ErrControlChars, ComponentInput, i)
 	}
 
 	defer parseRecover(&err)
 
-	obj = new(EmailObj)
-	obj.len = len(s)
+	*obj = EmailObj{prefixes: obj.prefixes[:0], len: len(s)}
 
 	var buf [254]byte
 	bufLen := 0
 	var status, tag byte
+	domStart := 0
 
-	for i := 0; i < len(s); i++ {
+	start := 0
+	if p.Quoted && len(s) > 0 && s[0] == '"' {
+		obj.login, obj.quoted, start, err = parseQuotedLogin(s, p)
+		if err != nil {
+			return
+		}
+		if loginMax := p.loginLenMax(); start-1 > loginMax {
+			err = newParseError(s, ErrLoginLenMax, ComponentLogin, loginMax)
+			return
+		}
+		status = 1
+		domStart = start
+	}
+
+	for i := start; i < len(s); i++ {
 		c := s[i]
 
 		if c >= 'A' && c <= 'Z' {
@@ -130,44 +292,61 @@ func parse(s string, isShot bool) (obj *EmailObj, err error) {
 
 		switch c {
 		case '@':
-			if tag != 0 && !isShot {
-				obj.prefixes = append(obj.prefixes, EmailPrefixObj{char: tag, text: string(buf[:bufLen])})
+			if loginMax := p.loginLenMax(); status != 1 && i > loginMax {
+				err = newParseError(s, ErrLoginLenMax, ComponentLogin, loginMax)
+				return
+			}
+			if tag != 0 {
+				if bad := tagBadIndex(buf[:bufLen], p.EAI); bad >= 0 {
+					err = newParseError(s, ErrInvalidLoginChars, ComponentTag, i-bufLen+bad)
+					return
+				}
+				if !isShot {
+					obj.prefixes = append(obj.prefixes, EmailPrefixObj{char: tag, text: in.string(buf[:bufLen])})
+				}
 				tag = 0
 			} else {
 				if bufLen == 0 {
-					err = ErrInvalidLogin
+					err = newParseError(s, ErrInvalidLogin, ComponentLogin, i)
 					return
 				}
 				if len(obj.login) > 0 {
-					err = ErrManyA
+					err = newParseError(s, ErrManyA, ComponentInput, i)
 					return
 				}
 
-				obj.login = string(buf[:bufLen])
-				if !isValidLogin(obj.login) {
-					err = ErrInvalidLoginChars
+				obj.login, err = loginFrom(buf[:bufLen], p, in)
+				if err != nil {
+					err = newParseError(s, err, ComponentLogin, loginBadIndex(bytesString(buf[:bufLen]), p))
 					return
 				}
 			}
 
 			bufLen = 0
 			status = 1
+			domStart = i + 1
 
 		case '+', '=':
 			if len(obj.login) == 0 {
 				if bufLen == 0 {
-					err = ErrInvalidLogin
+					err = newParseError(s, ErrInvalidLogin, ComponentLogin, i)
 					return
 				}
 
-				obj.login = string(buf[:bufLen])
-				if !isValidLogin(obj.login) {
-					err = ErrInvalidLoginChars
+				obj.login, err = loginFrom(buf[:bufLen], p, in)
+				if err != nil {
+					err = newParseError(s, err, ComponentLogin, loginBadIndex(bytesString(buf[:bufLen]), p))
 					return
 				}
 
-			} else if tag != 0 && !isShot {
-				obj.prefixes = append(obj.prefixes, EmailPrefixObj{char: tag, text: string(buf[:bufLen])})
+			} else if tag != 0 {
+				if bad := tagBadIndex(buf[:bufLen], p.EAI); bad >= 0 {
+					err = newParseError(s, ErrInvalidLoginChars, ComponentTag, i-bufLen+bad)
+					return
+				}
+				if !isShot {
+					obj.prefixes = append(obj.prefixes, EmailPrefixObj{char: tag, text: in.string(buf[:bufLen])})
+				}
 			}
 			tag = c
 			bufLen = 0
@@ -180,9 +359,13 @@ func parse(s string, isShot bool) (obj *EmailObj, err error) {
 	}
 
 	if tag != 0 {
+		if bad := tagBadIndex(buf[:bufLen], p.EAI); bad >= 0 {
+			err = newParseError(s, ErrInvalidLoginChars, ComponentTag, len(s)-bufLen+bad)
+			return
+		}
 		if !isShot {
 			obj.prefixes = append(obj.prefixes,
-				EmailPrefixObj{char: tag, text: string(buf[:bufLen])})
+				EmailPrefixObj{char: tag, text: in.string(buf[:bufLen])})
 		}
 		tag = 0
 		bufLen = 0
@@ -191,23 +374,95 @@ func parse(s string, isShot bool) (obj *EmailObj, err error) {
 	switch status {
 	case 1:
 		if bufLen == 0 {
-			err = ErrInvalidDomain
+			err = newParseError(s, ErrInvalidDomain, ComponentDomain, len(s))
+			return
+		}
+		obj.domain = in.string(buf[:bufLen])
+
+		if isLiteralDomain(obj.domain) {
+			if !p.Literals {
+				err = newParseError(s, ErrInvalidDomainChars, ComponentDomain, domStart)
+				return
+			}
+
+			var ok bool
+			obj.ip, obj.domain, ok = parseLiteral(obj.domain)
+			if !ok {
+				err = newParseError(s, ErrInvalidLiteral, ComponentDomain, domStart)
+				return
+			}
+			if p.RejectPrivateIP && isPrivateIP(obj.ip) {
+				err = newParseError(s, ErrPrivateIP, ComponentDomain, domStart)
+				return
+			}
+
+			obj.utf8 = !isASCII(obj.login)
 			return
 		}
-		obj.domain = string(buf[:bufLen])
+
+		converted := false
+		if !isASCII(obj.domain) {
+			if p.DisableIDN {
+				err = newParseError(s, ErrInvalidDomainChars, ComponentDomain, domStart+nonASCIIIndex(obj.domain))
+				return
+			}
+
+			ascii, ok := domainToASCII(obj.domain)
+			if !ok {
+				err = newParseError(s, ErrInvalidDomainChars, ComponentDomain, domStart)
+				return
+			}
+			obj.len += len(ascii) - len(obj.domain)
+			obj.domain = ascii
+			converted = true
+
+			if obj.len > p.lenMax() {
+				err = newParseError(s, ErrLenMax, ComponentInput, len(s))
+				return
+			}
+		}
 
 		if !isValidDomain(obj.domain) {
-			err = ErrInvalidDomainChars
+			offset, comp := domStart, ComponentDomain
+			if !converted {
+				offset, comp = domainBadIndex(obj.domain)
+				offset += domStart
+			}
+			err = newParseError(s, ErrInvalidDomainChars, comp, offset)
+			return
+		}
+		if p.StrictTLD && !isKnownTLD(obj.domain) {
+			offset := domStart
+			if !converted {
+				offset += strings.LastIndexByte(obj.domain, '.') + 1
+			}
+			err = newParseError(s, ErrUnknownTLD, ComponentLabel, offset)
 			return
 		}
+		if p.RejectPublicSuffix && isPublicSuffix(obj.domain) {
+			err = newParseError(s, ErrPublicSuffix, ComponentDomain, domStart)
+			return
+		}
+		if p.RejectSpecialUse && isSpecialUseDomain(obj.domain) {
+			err = newParseError(s, ErrSpecialUse, ComponentDomain, domStart)
+			return
+		}
+		if p.Providers {
+			if bad := providerLoginBadIndex(obj.login, obj.domain); bad >= 0 {
+				err = newParseError(s, ErrProviderLogin, ComponentLogin, loginOffset(s, bad))
+				return
+			}
+		}
+
+		obj.utf8 = !isASCII(obj.login)
 		return
 
 	case 2:
-		err = ErrEndToTag
+		err = newParseError(s, ErrEndToTag, ComponentTag, len(s))
 		return
 
 	default:
-		err = ErrEndToEOF
+		err = newParseError(s, ErrEndToEOF, ComponentInput, len(s))
 		return
 	}
 }
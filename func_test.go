@@ -1,8 +1,10 @@
 package puremail
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"testing"
@@ -346,3 +348,1272 @@ func BenchmarkParse(b *testing.B) {
 
 	}
 }
+
+func BenchmarkParseBytes(b *testing.B) {
+	rows := make([][]byte, 1000)
+	for i := range rows {
+		rows[i] = fmt.Appendf(nil, "user%03d+tag%03d@host0.com", i, i)
+	}
+
+	b.Run("String", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, row := range rows {
+				if _, err := New(string(row)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("NewBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			for _, row := range rows {
+				if _, err := NewBytes(row); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("AppendParse", func(b *testing.B) {
+		var (
+			obj   EmailObj
+			arena = make([]byte, 0, 64<<10)
+			err   error
+		)
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			arena = arena[:0]
+			for _, row := range rows {
+				if arena, err = AppendParse(arena, &obj, row); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
+
+//
+
+func withParseConf(t testing.TB, set func(p *ConfigParseObj)) {
+	old := conf.Parse
+	set(&conf.Parse)
+	t.Cleanup(func() { conf.Parse = old })
+}
+
+func TestPublicSuffix(t *testing.T) {
+	cases := []struct {
+		domain, suffix, registrable string
+	}{
+		{"mail.corp.example.co.uk", "co.uk", "example.co.uk"},
+		{"example.com", "com", "example.com"},
+		{"co.uk", "co.uk", ""},
+		{"foo.bar.ck", "bar.ck", "foo.bar.ck"},
+		{"www.ck", "ck", "www.ck"},
+		{"a.b.city.kawasaki.jp", "kawasaki.jp", "city.kawasaki.jp"},
+		{"a.b.other.kawasaki.jp", "other.kawasaki.jp", "b.other.kawasaki.jp"},
+		{"user.github.io", "github.io", "user.github.io"},
+		{"example.xn--p1ai", "xn--p1ai", "example.xn--p1ai"},
+		{"host.unknowntld", "unknowntld", "host.unknowntld"},
+		{"localhost", "localhost", ""},
+	}
+
+	for _, tc := range cases {
+		obj := &EmailObj{login: "a", domain: tc.domain}
+		if got := obj.PublicSuffix(); got != tc.suffix {
+			t.Errorf("PublicSuffix(%q) = %q, want %q", tc.domain, got, tc.suffix)
+		}
+		if got := obj.RegistrableDomain(); got != tc.registrable {
+			t.Errorf("RegistrableDomain(%q) = %q, want %q", tc.domain, got, tc.registrable)
+		}
+	}
+}
+
+func TestRejectPublicSuffix(t *testing.T) {
+	if _, err := parse("user@co.uk", false); err != nil {
+		t.Fatalf("default config must accept bare suffix, got %v", err)
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.RejectPublicSuffix = true })
+
+	if _, err := parse("user@co.uk", false); !errors.Is(err, ErrPublicSuffix) {
+		t.Fatalf("want ErrPublicSuffix, got %v", err)
+	}
+	if _, err := parse("user@example.co.uk", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadPSL(t *testing.T) {
+	old := pslCurrent()
+	t.Cleanup(func() { psl.Store(old) })
+
+	if err := LoadPSL(strings.NewReader("// test list\ncom\n*.example\n!keep.example\n")); err != nil {
+		t.Fatalf("LoadPSL: %v", err)
+	}
+	if got := registrableDomain("a.b.example"); got != "a.b.example" {
+		t.Errorf("wildcard: got %q", got)
+	}
+	if got := registrableDomain("x.keep.example"); got != "keep.example" {
+		t.Errorf("exception: got %q", got)
+	}
+}
+
+func TestStrictTLD(t *testing.T) {
+	for _, mail := range []string{"user@example.notatld", "user@gmail.con"} {
+		if _, err := parse(mail, false); err != nil {
+			t.Fatalf("default config must accept %q, got %v", mail, err)
+		}
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.StrictTLD = true })
+
+	for _, mail := range []string{"user@example.notatld", "user@gmail.con", "bob@localhost"} {
+		if _, err := parse(mail, false); !errors.Is(err, ErrUnknownTLD) {
+			t.Errorf("parse(%q): want ErrUnknownTLD, got %v", mail, err)
+		}
+	}
+	for _, mail := range []string{"user@gmail.com", "user@example.co.uk", "user@xn--e1afmkfd.xn--j1amh", "user@Example.IO"} {
+		if _, err := parse(mail, false); err != nil {
+			t.Errorf("parse(%q): unexpected error %v", mail, err)
+		}
+	}
+}
+
+func TestLoadTLD(t *testing.T) {
+	t.Cleanup(func() {
+		f, _ := dataFS.Open("data/tlds.txt")
+		defer f.Close()
+		tldSet.replace(f)
+	})
+
+	if err := LoadTLD(strings.NewReader("# Version 1\nCOM\nNEWTLD\n")); err != nil {
+		t.Fatalf("LoadTLD: %v", err)
+	}
+	if !isKnownTLD("shop.newtld") || isKnownTLD("example.org") {
+		t.Fatalf("replaced list not applied")
+	}
+
+	// "com" is in both lists, so a reader must never miss it while the list is swapped
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				if !isKnownTLD("example.com") {
+					t.Errorf("reader saw a partially loaded list")
+					return
+				}
+			}
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		LoadTLD(strings.NewReader("COM\nNEWTLD\n"))
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestRejectSpecialUse(t *testing.T) {
+	if _, err := parse("x@foo.localhost", false); err != nil {
+		t.Fatalf("default config must accept special-use names, got %v", err)
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.RejectSpecialUse = true })
+
+	for _, mail := range []string{"a@example.com", "x@foo.localhost", "u@site.test", "u@host.onion"} {
+		if _, err := parse(mail, false); !errors.Is(err, ErrSpecialUse) {
+			t.Errorf("parse(%q): want ErrSpecialUse, got %v", mail, err)
+		}
+	}
+}
+
+func TestParseIDN(t *testing.T) {
+	cases := []struct {
+		input, domain, unicode string
+	}{
+		{"user@ПРИКЛАД.укр", "xn--80aikifvh.xn--j1amh", "приклад.укр"},
+		{"user@bücher.de", "xn--bcher-kva.de", "bücher.de"},
+		{"user@例え。テスト", "xn--r8jz45g.xn--zckzah", "例え.テスト"},
+		{"user@xn--e1afmkfd.xn--j1amh", "xn--e1afmkfd.xn--j1amh", "пример.укр"},
+		{"user@example.com", "example.com", "example.com"},
+	}
+
+	for _, tc := range cases {
+		obj, err := parse(tc.input, false)
+		if err != nil {
+			t.Errorf("parse(%q): %v", tc.input, err)
+			continue
+		}
+		if obj.Domain() != tc.domain {
+			t.Errorf("Domain(%q) = %q, want %q", tc.input, obj.Domain(), tc.domain)
+		}
+		if obj.DomainUnicode() != tc.unicode {
+			t.Errorf("DomainUnicode(%q) = %q, want %q", tc.input, obj.DomainUnicode(), tc.unicode)
+		}
+	}
+
+	for _, input := range []string{"user@xn--abc-.com", "user@xn--zz.com", "user@xn--a-ecp.ru", "user@a_b.com", "привіт@приклад.укр"} {
+		if _, err := parse(input, false); err == nil {
+			t.Errorf("parse(%q): an error expected", input)
+		}
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.DisableIDN = true })
+	if _, err := parse("user@bücher.de", false); !errors.Is(err, ErrInvalidDomainChars) {
+		t.Errorf("DisableIDN: want ErrInvalidDomainChars, got %v", err)
+	}
+}
+
+func TestParseEAI(t *testing.T) {
+	if _, err := parse("пошта@приклад.укр", false); !errors.Is(err, ErrInvalidLoginChars) {
+		t.Fatalf("EAI is opt-in, got %v", err)
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.EAI = true })
+
+	cases := []struct {
+		input, login, domain string
+	}{
+		{"пошта@приклад.укр", "пошта", "xn--80aikifvh.xn--j1amh"},
+		{"ПОШТА+робота@приклад.укр", "пошта", "xn--80aikifvh.xn--j1amh"},
+		{"用户@例子.广告", "用户", "xn--fsqu00a.xn--4rr70v"},
+		{"josé@example.com", "josé", "example.com"},
+		{"ascii.user@example.com", "ascii.user", "example.com"},
+	}
+	for _, tc := range cases {
+		obj, err := parse(tc.input, false)
+		if err != nil {
+			t.Errorf("parse(%q): %v", tc.input, err)
+			continue
+		}
+		if obj.Login() != tc.login || obj.Domain() != tc.domain {
+			t.Errorf("parse(%q) = %q@%q, want %q@%q", tc.input, obj.Login(), obj.Domain(), tc.login, tc.domain)
+		}
+		if obj.SMTPUTF8() != !isASCII(tc.login) {
+			t.Errorf("SMTPUTF8(%q) = %v", tc.input, obj.SMTPUTF8())
+		}
+
+		back, err := Decode(obj.Bytes())
+		if err != nil || back.Mail() != obj.Mail() || back.SMTPUTF8() != obj.SMTPUTF8() {
+			t.Errorf("Bytes/Decode(%q) = %v, %v", tc.input, back, err)
+		}
+		if back.Hash() != obj.Hash() {
+			t.Errorf("Hash(%q) differs after round-trip", tc.input)
+		}
+	}
+
+	nfc, _ := parse("josé@example.com", false)
+	nfd, _ := parse("jose\u0301@example.com", false)
+	if nfc.Hash() != nfd.Hash() {
+		t.Errorf("NFC and NFD inputs must hash equally")
+	}
+
+	for _, input := range []string{"по..шта@example.com", "\u200buser@example.com", "пош\u00a0та@example.com", "\xff\xfe@example.com", ".пошта@example.com"} {
+		if _, err := parse(input, false); !errors.Is(err, ErrInvalidLoginChars) {
+			t.Errorf("parse(%q): want ErrInvalidLoginChars, got %v", input, err)
+		}
+	}
+}
+
+func TestParseQuoted(t *testing.T) {
+	if _, err := parse(`"john doe"@example.com`, false); err == nil {
+		t.Fatalf("quoted logins are opt-in")
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.Quoted = true })
+
+	cases := []struct {
+		input, login, wire string
+	}{
+		{`"John Doe"@example.com`, "john doe", `"john doe"`},
+		{`"a@b"@example.com`, "a@b", `"a@b"`},
+		{`"say \"hi\""@example.com`, `say "hi"`, `"say \"hi\""`},
+		{`"back\\slash"@example.com`, `back\slash`, `"back\\slash"`},
+		{`"a+b"@example.com`, "a+b", `"a+b"`},
+		{`"plain"@example.com`, "plain", ""},
+		{`"a..b"@example.com`, "a..b", `"a..b"`},
+	}
+	for _, tc := range cases {
+		obj, err := parse(tc.input, false)
+		if err != nil {
+			t.Errorf("parse(%q): %v", tc.input, err)
+			continue
+		}
+		if obj.Login() != tc.login {
+			t.Errorf("Login(%q) = %q, want %q", tc.input, obj.Login(), tc.login)
+		}
+
+		wire := tc.wire
+		if wire == "" {
+			wire = tc.login
+		}
+		if obj.LoginQuoted() != wire || obj.IsQuoted() != (tc.wire != "") {
+			t.Errorf("LoginQuoted(%q) = %q, want %q", tc.input, obj.LoginQuoted(), wire)
+		}
+		if obj.Mail() != wire+"@example.com" || obj.MailFull() != obj.Mail() {
+			t.Errorf("Mail(%q) = %q / %q", tc.input, obj.Mail(), obj.MailFull())
+		}
+
+		again, err := parse(obj.Mail(), false)
+		if err != nil || again.Login() != obj.Login() || again.Hash() != obj.Hash() {
+			t.Errorf("re-parse(%q) = %v, %v", obj.Mail(), again, err)
+		}
+
+		back, err := Decode(obj.Bytes())
+		if err != nil || back.Login() != obj.Login() || back.Mail() != obj.Mail() {
+			t.Errorf("Bytes/Decode(%q) = %v, %v", tc.input, back, err)
+		}
+	}
+
+	for _, input := range []string{`"unterminated@example.com`, `"a"b@example.com`, `""@example.com`, "\"tab\there\"@example.com", `"bad\`} {
+		if _, err := parse(input, false); err == nil {
+			t.Errorf("parse(%q): an error expected", input)
+		}
+	}
+}
+
+func TestParseLiteral(t *testing.T) {
+	if _, err := parse("postmaster@[192.0.2.1]", false); err == nil {
+		t.Fatalf("address literals are opt-in")
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.Literals = true })
+
+	cases := []struct {
+		input, domain, ip string
+	}{
+		{"postmaster@[192.0.2.1]", "[192.0.2.1]", "192.0.2.1"},
+		{"user@[IPv6:2001:DB8::1]", "[IPv6:2001:db8::1]", "2001:db8::1"},
+		{"user@[ipv6:2001:db8:0:0:0:0:0:1]", "[IPv6:2001:db8::1]", "2001:db8::1"},
+		{"user@[127.0.0.1]", "[127.0.0.1]", "127.0.0.1"},
+	}
+	for _, tc := range cases {
+		obj, err := parse(tc.input, false)
+		if err != nil {
+			t.Errorf("parse(%q): %v", tc.input, err)
+			continue
+		}
+		if obj.Domain() != tc.domain || obj.IP().String() != tc.ip {
+			t.Errorf("parse(%q) = %q / %v, want %q / %s", tc.input, obj.Domain(), obj.IP(), tc.domain, tc.ip)
+		}
+		if err := obj.HasMX(); err != nil {
+			t.Errorf("HasMX(%q) must not need DNS, got %v", tc.input, err)
+		}
+
+		back, err := Decode(obj.Bytes())
+		if err != nil || back.IP() != obj.IP() || back.Mail() != obj.Mail() {
+			t.Errorf("Bytes/Decode(%q) = %v, %v", tc.input, back, err)
+		}
+	}
+
+	for _, input := range []string{"user@[192.0.2]", "user@[IPv6:192.0.2.1]", "user@[2001:db8::1]", "user@[IPv6:fe80::1%eth0]", "user@[192.0.2.1"} {
+		if _, err := parse(input, false); err == nil {
+			t.Errorf("parse(%q): an error expected", input)
+		}
+	}
+
+	if obj, _ := parse("user@example.com", false); obj.IP().IsValid() {
+		t.Errorf("regular domains have no IP")
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.RejectPrivateIP = true })
+	for _, input := range []string{"user@[127.0.0.1]", "user@[10.1.2.3]", "user@[192.168.0.1]", "user@[IPv6:::1]", "user@[IPv6:fe80::1]"} {
+		if _, err := parse(input, false); !errors.Is(err, ErrPrivateIP) {
+			t.Errorf("parse(%q): want ErrPrivateIP, got %v", input, err)
+		}
+	}
+	if _, err := parse("user@[192.0.2.1]", false); err != nil {
+		t.Errorf("public literal rejected: %v", err)
+	}
+}
+
+func TestParseErrorDetails(t *testing.T) {
+	tests := []struct {
+		input     string
+		sentinel  error
+		code      string
+		component ParseComponent
+		offset    int
+		char      rune
+	}{
+		{strings.Repeat("x", 255), ErrLenMax, CodeLenMax, ComponentInput, 254, 'x'},
+		{"@domain.com", ErrInvalidLogin, CodeInvalidLogin, ComponentLogin, 0, '@'},
+		{"a@b@c.com", ErrManyA, CodeManyA, ComponentInput, 3, '@'},
+		{"me(you)@mail.net", ErrInvalidLoginChars, CodeInvalidLoginChars, ComponentLogin, 2, '('},
+		{"us..er@domain.com", ErrInvalidLoginChars, CodeInvalidLoginChars, ComponentLogin, 3, '.'},
+		{"user.@domain.com", ErrInvalidLoginChars, CodeInvalidLoginChars, ComponentLogin, 4, '.'},
+		{"Bob..smith@x.com", ErrInvalidLoginChars, CodeInvalidLoginChars, ComponentLogin, 4, '.'},
+		{"ABC(d@x.com", ErrInvalidLoginChars, CodeInvalidLoginChars, ComponentLogin, 3, '('},
+		{"ABC(d+tag@x.com", ErrInvalidLoginChars, CodeInvalidLoginChars, ComponentLogin, 3, '('},
+		{"user@", ErrInvalidDomain, CodeInvalidDomain, ComponentDomain, 5, 0},
+		{"user@exa^mple.com", ErrInvalidDomainChars, CodeInvalidDomainChars, ComponentDomain, 8, '^'},
+		{"user@mail.-bad.com", ErrInvalidDomainChars, CodeInvalidDomainChars, ComponentLabel, 10, '-'},
+		{"user+tag", ErrEndToTag, CodeEndToTag, ComponentTag, 8, 0},
+		{"justlogin", ErrEndToEOF, CodeEndToEOF, ComponentInput, 9, 0},
+	}
+
+	for _, tt := range tests {
+		_, err := parse(tt.input, false)
+		if !errors.Is(err, tt.sentinel) {
+			t.Errorf("parse(%q): want %v, got %v", tt.input, tt.sentinel, err)
+			continue
+		}
+
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("parse(%q): %T is not *ParseError", tt.input, err)
+			continue
+		}
+		if pe.Code != tt.code || pe.Component != tt.component || pe.Offset != tt.offset || pe.Char != tt.char {
+			t.Errorf("parse(%q) = {%s %s %d %q}, want {%s %s %d %q}", tt.input,
+				pe.Code, pe.Component, pe.Offset, pe.Char, tt.code, tt.component, tt.offset, tt.char)
+		}
+		if ErrorCode(err) != tt.code {
+			t.Errorf("ErrorCode(%q) = %q", tt.input, ErrorCode(err))
+		}
+	}
+
+	if ErrorCode(ErrCRC) != CodeCRC || ErrorCode(errors.New("foreign")) != "" {
+		t.Errorf("ErrorCode on plain errors")
+	}
+}
+
+func TestParseErrorStrictTLD(t *testing.T) {
+	withParseConf(t, func(p *ConfigParseObj) { p.StrictTLD = true })
+
+	_, err := parse("user@gmail.con", false)
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Component != ComponentLabel || pe.Offset != 11 {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := err.Error(); got != "unknown top-level domain 'c' at offset 11 (label)" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	_, err := parse("me(you)@mail.net", false)
+
+	cases := map[string]string{
+		"en":    "The part before the @ sign contains characters that are not allowed.",
+		"uk":    "Частина адреси перед знаком @ містить недопустимі символи.",
+		"ru-RU": "Часть адреса перед знаком @ содержит недопустимые символы.",
+		"UK_ua": "Частина адреси перед знаком @ містить недопустимі символи.",
+		"xx":    "The part before the @ sign contains characters that are not allowed.",
+	}
+	for lang, want := range cases {
+		if got := Message(err, lang); got != want {
+			t.Errorf("Message(%s) = %q, want %q", lang, got, want)
+		}
+	}
+
+	RegisterLanguage("pl", map[string]string{
+		CodeInvalid:           "Adres e-mail jest nieprawidłowy.",
+		CodeInvalidLoginChars: "Część przed znakiem @ zawiera niedozwolone znaki.",
+	})
+	if got := Message(err, "pl"); got != "Część przed znakiem @ zawiera niedozwolone znaki." {
+		t.Errorf("registered language: %q", got)
+	}
+	if _, err := parse("a@b@c.com", false); Message(err, "pl") != "Adres e-mail jest nieprawidłowy." {
+		t.Errorf("registered language fallback: %q", Message(err, "pl"))
+	}
+
+	if got := Message(errors.New("foreign"), "uk"); got != "Адреса електронної пошти некоректна." {
+		t.Errorf("foreign error: %q", got)
+	}
+	if got := Message(mxError("x.io", ErrNilMX), "en"); got != "This domain cannot receive email." {
+		t.Errorf("MX error: %q", got)
+	}
+	if Message(nil, "en") != "" {
+		t.Errorf("nil error must render empty")
+	}
+
+	for lang, catalogue := range messages {
+		for _, ec := range errCodes {
+			if _, ok := catalogue[ec.code]; !ok && (lang == "en" || lang == "uk" || lang == "ru") {
+				t.Errorf("%s: missing message for %s", lang, ec.code)
+			}
+		}
+	}
+}
+
+func TestParseMailbox(t *testing.T) {
+	cases := []struct {
+		in, name, mail string
+	}{
+		{"alice@example.io", "", "alice@example.io"},
+		{"Alice <alice@example.io>", "Alice", "alice@example.io"},
+		{"  Alice  Smith   <Alice@Example.io> ", "Alice Smith", "alice@example.io"},
+		{`"Smith, Alice" <alice@example.io>`, "Smith, Alice", "alice@example.io"},
+		{`"Al \"Ace\" Smith" <alice@example.io>`, `Al "Ace" Smith`, "alice@example.io"},
+		{"alice@example.io (Alice)", "Alice", "alice@example.io"},
+		{"Alice (work) <alice@example.io> (ignored)", "Alice", "alice@example.io"},
+		{"<alice+news@example.io>", "", "alice+news@example.io"},
+		{"=?UTF-8?B?0J7Qu9C10LrRgdCw0L3QtNGA?= <olex@example.io>", "Олександр", "olex@example.io"},
+		{"=?utf-8?q?Ren=C3=A9?= =?utf-8?q?_Dupont?= <rene@example.io>", "René Dupont", "rene@example.io"},
+	}
+	for _, c := range cases {
+		m, err := ParseMailbox(c.in)
+		if err != nil {
+			t.Errorf("ParseMailbox(%q): %v", c.in, err)
+			continue
+		}
+		if m.Name() != c.name || m.Email().MailFull() != c.mail {
+			t.Errorf("ParseMailbox(%q) = %q %q, want %q %q", c.in, m.Name(), m.Email().MailFull(), c.name, c.mail)
+		}
+	}
+
+	for _, in := range []string{
+		"", "Alice <alice@example.io", "Alice alice@example.io>",
+		`"Alice <alice@example.io>`, "<a@example.io> <b@example.io>",
+		"Alice <alice@example.io> Bob", "(Alice alice@example.io", "a@example.io b@example.io",
+	} {
+		if _, err := ParseMailbox(in); !errors.Is(err, ErrInvalidMailbox) {
+			t.Errorf("ParseMailbox(%q): expected ErrInvalidMailbox, got %v", in, err)
+		}
+	}
+
+	if _, err := ParseMailbox("Alice <not-an-address>"); !errors.Is(err, ErrEndToEOF) {
+		t.Errorf("address errors must come from New, got %v", err)
+	}
+
+	var pe *ParseError
+	for in, off := range map[string]int{
+		" Alice < bob..x@corp.io>": 13,
+		"bob..x@corp.io (Bob)":     4,
+		`"john doe"@corp.io`:       0,
+	} {
+		if _, err := ParseMailbox(in); !errors.As(err, &pe) || pe.Offset != off || rune(in[off]) != pe.Char {
+			t.Errorf("ParseMailbox(%q): %v, want offset %d", in, err, off)
+		}
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.Quoted = true })
+	for _, in := range []string{`"john doe"@corp.io`, `"john doe"@corp.io (John)`, `John <"john doe"@corp.io>`} {
+		if m, err := ParseMailbox(in); err != nil || m.Email().Login() != "john doe" {
+			t.Errorf("ParseMailbox(%q): %v", in, err)
+		}
+	}
+}
+
+func TestMailboxString(t *testing.T) {
+	email := &EmailObj{login: "alice", domain: "example.io", len: 16}
+
+	cases := map[string]string{
+		"":                    "alice@example.io",
+		"Alice Smith":         "Alice Smith <alice@example.io>",
+		"Smith, Alice":        `"Smith, Alice" <alice@example.io>`,
+		`Al "Ace"`:            `"Al \"Ace\"" <alice@example.io>`,
+		"Олександр":           "=?utf-8?q?=D0=9E=D0=BB=D0=B5=D0=BA=D1=81=D0=B0=D0=BD=D0=B4=D1=80?= <alice@example.io>",
+		"Evil\r\nBcc: x@y.io": `"Evil Bcc: x@y.io" <alice@example.io>`,
+		"=?utf-8?q?fake?=":    `"=?utf-8?q?fake?=" <alice@example.io>`,
+		"\r\n\t ":             "alice@example.io",
+	}
+	for name, want := range cases {
+		m := NewMailbox(name, email)
+		got := m.String()
+		if got != want {
+			t.Errorf("String(%q) = %q, want %q", name, got, want)
+		}
+		if strings.ContainsAny(got, "\r\n") {
+			t.Errorf("String(%q) leaks a line break", name)
+		}
+
+		back, err := ParseMailbox(got)
+		if err != nil || back.Email().MailFull() != email.MailFull() {
+			t.Errorf("ParseMailbox(%q) round trip: %v", got, err)
+		}
+	}
+}
+
+func TestParseAddressList(t *testing.T) {
+	list, errs := ParseAddressList(`a@x.io, "B, Jr." <b@y.io>, team: c@z.io, "D" (lead) <d@z.io>;, undisclosed-recipients:;, e@w.io (E, the one)`)
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	want := []struct{ name, group, mail string }{
+		{"", "", "a@x.io"},
+		{"B, Jr.", "", "b@y.io"},
+		{"", "team", "c@z.io"},
+		{"D", "team", "d@z.io"},
+		{"E, the one", "", "e@w.io"},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("got %d mailboxes, want %d", len(list), len(want))
+	}
+	for i, w := range want {
+		m := list[i]
+		if m.Name() != w.name || m.Group() != w.group || m.Email().Mail() != w.mail {
+			t.Errorf("#%d = %q %q %q, want %q %q %q", i, m.Name(), m.Group(), m.Email().Mail(), w.name, w.group, w.mail)
+		}
+	}
+
+	list, errs = ParseAddressList(`a@x.io, broken, , Bob <bob@>, c@z.io, x: y: z@z.io;, "open`)
+	if len(list) != 2 || list[0].Email().Mail() != "a@x.io" || list[1].Email().Mail() != "c@z.io" {
+		t.Errorf("good entries must survive bad ones: %v", list)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("got %d errors, want 4: %v", len(errs), errs)
+	}
+	if errs[0].Index != 1 || errs[0].Raw != "broken" || !errors.Is(errs[0], ErrEndToEOF) {
+		t.Errorf("errs[0] = %+v", errs[0])
+	}
+	if errs[1].Index != 2 || errs[1].Raw != "Bob <bob@>" {
+		t.Errorf("errs[1] = %+v", errs[1])
+	}
+	if errs[2].Group != "x" || !errors.Is(errs[2], ErrInvalidMailbox) {
+		t.Errorf("errs[2] = %+v", errs[2])
+	}
+	if !errors.Is(errs[3], ErrInvalidMailbox) {
+		t.Errorf("errs[3] = %+v", errs[3])
+	}
+
+	if list, errs := ParseAddressList(""); len(list) != 0 || len(errs) != 0 {
+		t.Errorf("empty header: %v %v", list, errs)
+	}
+	if _, errs := ParseAddressList("a@x.io;"); len(errs) != 1 {
+		t.Errorf("stray ';' must be reported")
+	}
+}
+
+func TestExtract(t *testing.T) {
+	text := "Hi <Alice@Example.io>, cc bob+ops@mail.co.uk. See icon@2x.png, logo@3x.zip and " +
+		"user@localhost; (mailto:carol@пример.укр) https://u:p@host.com/x a@b@c.com \"dave@corp.net\"."
+
+	want := []string{"alice@example.io", "bob+ops@mail.co.uk", "carol@" + domainMust("пример.укр"), "dave@corp.net"}
+	got := Extract(text)
+	if len(got) != len(want) {
+		t.Fatalf("got %d matches, want %d: %v", len(got), len(want), got)
+	}
+	for i, m := range got {
+		if m.Email.MailFull() != want[i] {
+			t.Errorf("#%d = %q, want %q", i, m.Email.MailFull(), want[i])
+		}
+		if !strings.EqualFold(text[m.Start:m.End], m.Email.MailFull()) && i != 2 {
+			t.Errorf("#%d offsets %d..%d cover %q", i, m.Start, m.End, text[m.Start:m.End])
+		}
+	}
+	if text[got[2].Start:got[2].End] != "carol@пример.укр" {
+		t.Errorf("unicode match covers %q", text[got[2].Start:got[2].End])
+	}
+
+	if m := Extract("...alice@example.io..."); len(m) != 1 || m[0].Start != 3 || m[0].End != 19 {
+		t.Errorf("dots around a match: %v", m)
+	}
+
+	r := strings.NewReader("first line\r\nwrite to team@corp.net\nor ops@corp.net")
+	ms, err := ExtractReader(r)
+	if err != nil || len(ms) != 2 {
+		t.Fatalf("ExtractReader: %v %v", ms, err)
+	}
+	if ms[0].Start != 21 || ms[1].Start != 38 {
+		t.Errorf("ExtractReader offsets: %d %d", ms[0].Start, ms[1].Start)
+	}
+
+	long := io.MultiReader(strings.NewReader("ops@corp.net\n"), strings.NewReader(strings.Repeat("x", extractLineMax+1)))
+	if ms, err = ExtractReader(long); !errors.Is(err, bufio.ErrTooLong) || len(ms) != 1 {
+		t.Errorf("ExtractReader on an endless line: %v %v", ms, err)
+	}
+}
+
+func TestDeobfuscate(t *testing.T) {
+	cases := []struct {
+		in, mail string
+		applied  Deobfuscation
+	}{
+		{"alice@example.io", "alice@example.io", 0},
+		{"alice [at] example [dot] io", "alice@example.io", DeobfuscateAt | DeobfuscateDot | DeobfuscateSpaces},
+		{"alice(at)example.io", "alice@example.io", DeobfuscateAt},
+		{"alice{AT}example{.}io", "alice@example.io", DeobfuscateAt | DeobfuscateDot},
+		{"alice at example dot co dot uk", "alice@example.co.uk", DeobfuscateAt | DeobfuscateDot | DeobfuscateSpaces},
+		{"alice @ example.io", "alice@example.io", DeobfuscateSpaces},
+		{"alice&#64;example&#46;io", "alice@example.io", DeobfuscateHTML},
+		{"alice&commat;example.io", "alice@example.io", DeobfuscateHTML},
+		{"ivan собака mail точка ru", "ivan@mail.ru", DeobfuscateAt | DeobfuscateDot | DeobfuscateSpaces},
+		{"juan (arroba) correo (punto) es", "juan@correo.es", DeobfuscateAt | DeobfuscateDot | DeobfuscateSpaces},
+	}
+	for _, c := range cases {
+		obj, applied, err := Deobfuscate(c.in)
+		if err != nil {
+			t.Errorf("Deobfuscate(%q): %v", c.in, err)
+			continue
+		}
+		if obj.MailFull() != c.mail || applied != c.applied {
+			t.Errorf("Deobfuscate(%q) = %q %s, want %q %s", c.in, obj.MailFull(), applied, c.mail, c.applied)
+		}
+	}
+
+	if _, _, err := Deobfuscate("meet me at noon"); err == nil {
+		t.Errorf("prose without an address must fail")
+	}
+
+	AddObfuscationAt("shtrudel")
+	if obj, _, err := Deobfuscate("dov shtrudel example.co.il"); err != nil || obj.Mail() != "dov@example.co.il" {
+		t.Errorf("custom word: %v %v", obj, err)
+	}
+	if s := (DeobfuscateHTML | DeobfuscateDot).String(); s != "html|dot" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestParseMailto(t *testing.T) {
+	m, err := ParseMailto("MAILTO:alice@example.io,bob%2Bops@example.io?to=carol@example.io" +
+		"&cc=dave@example.io&BCC=eve@example.io&subject=Hello%20there%21&body=line%201%0D%0Aa+b&in-reply-to=%3C3469A91.D10AF4C@example.com%3E#frag")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mails := func(list []*EmailObj) (res []string) {
+		for _, obj := range list {
+			res = append(res, obj.MailFull())
+		}
+		return
+	}
+	if got := fmt.Sprint(mails(m.To)); got != "[alice@example.io bob+ops@example.io carol@example.io]" {
+		t.Errorf("To = %s", got)
+	}
+	if got := fmt.Sprint(mails(m.Cc), mails(m.Bcc)); got != "[dave@example.io] [eve@example.io]" {
+		t.Errorf("Cc/Bcc = %s", got)
+	}
+	if m.Subject != "Hello there!" || m.Body != "line 1\r\na+b" {
+		t.Errorf("Subject/Body = %q %q", m.Subject, m.Body)
+	}
+	if m.Headers["in-reply-to"] != "<3469A91.D10AF4C@example.com>" {
+		t.Errorf("Headers = %v", m.Headers)
+	}
+
+	if m, err := ParseMailto("mailto:?to=alice@%D0%BF%D1%80%D0%B8%D0%BC%D0%B5%D1%80.%D1%83%D0%BA%D1%80"); err != nil ||
+		m.To[0].Domain() != domainMust("пример.укр") {
+		t.Errorf("percent-encoded IDN: %v %v", m, err)
+	}
+
+	for _, in := range []string{"", "alice@example.io", "http://example.io", "mailto:a@b.io?subject", "mailto:%zz@b.io"} {
+		if _, err := ParseMailto(in); !errors.Is(err, ErrInvalidMailto) {
+			t.Errorf("ParseMailto(%q): expected ErrInvalidMailto, got %v", in, err)
+		}
+	}
+	if _, err := ParseMailto("mailto:not-an-address"); !errors.Is(err, ErrEndToEOF) {
+		t.Errorf("recipient errors must come from New, got %v", err)
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.Quoted = true })
+	quoted, err := New(`"a,b"@x.com`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := (&MailtoObj{To: []*EmailObj{quoted}, Cc: []*EmailObj{quoted}}).String()
+	if m, err = ParseMailto(uri); err != nil || len(m.To) != 1 || len(m.Cc) != 1 ||
+		m.To[0].Login() != "a,b" || m.Cc[0].Login() != "a,b" {
+		t.Errorf("ParseMailto(%q) round trip: %v %v", uri, m, err)
+	}
+}
+
+func TestMailtoString(t *testing.T) {
+	alice, _ := New("alice+news@example.io")
+	bob, _ := New("bob@example.io")
+
+	m := &MailtoObj{
+		To:      []*EmailObj{alice},
+		Cc:      []*EmailObj{bob},
+		Subject: "Q&A: 50% off?\r\nBcc: evil@example.io",
+		Body:    "Hi,\nsee you #soon",
+		Headers: map[string]string{"x-ref": "a/b"},
+	}
+	want := "mailto:alice+news@example.io?cc=bob@example.io&subject=Q%26A:%2050%25%20off%3F%20Bcc:%20evil@example.io" +
+		"&body=Hi%2C%0D%0Asee%20you%20%23soon&x-ref=a%2Fb"
+	if got := m.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+
+	back, err := ParseMailto(m.String())
+	if err != nil || back.To[0].MailFull() != "alice+news@example.io" || back.Subject != "Q&A: 50% off? Bcc: evil@example.io" ||
+		back.Body != "Hi,\r\nsee you #soon" || back.Headers["x-ref"] != "a/b" {
+		t.Errorf("round trip: %+v %v", back, err)
+	}
+
+	if got := bob.Mailto(); got != "mailto:bob@example.io" {
+		t.Errorf("Mailto() = %q", got)
+	}
+}
+
+func TestControlChars(t *testing.T) {
+	cases := []struct {
+		in   string
+		err  error
+		comp ParseComponent
+		off  int
+	}{
+		{"bob@example.io\r\nBcc: eve@example.io", ErrControlChars, ComponentInput, 14},
+		{"bob\x00@example.io", ErrControlChars, ComponentInput, 3},
+		{"bob@exam\x7fple.io", ErrControlChars, ComponentInput, 8},
+		{"bob+a b@example.io", ErrInvalidLoginChars, ComponentTag, 5},
+		{"bob+ok=<x>@example.io", ErrInvalidLoginChars, ComponentTag, 7},
+		{"bob+a,b", ErrInvalidLoginChars, ComponentTag, 5},
+	}
+	for _, c := range cases {
+		for _, shot := range []bool{false, true} {
+			_, err := parse(c.in, shot)
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Err != c.err || pe.Component != c.comp || pe.Offset != c.off {
+				t.Errorf("parse(%q, %v) = %v, want %v in %s at %d", c.in, shot, err, c.err, c.comp, c.off)
+			}
+		}
+	}
+
+	if obj, err := parse("bob+v1.2=ok@example.io", false); err != nil || obj.MailFull() != "bob+v1.2=ok@example.io" {
+		t.Errorf("dots in tags must stay valid: %v %v", obj, err)
+	}
+
+	blob := newObj("bob", "example.io", EmailPrefixObj{char: '+', text: "a\r\nb"}).Bytes()
+	if _, err := Decode(blob); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Decode must reject control characters, got %v", err)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := map[string]string{
+		"  alice@example.io\r\n":            "alice@example.io",
+		"\ufeffalice@exa\u200bmple.io":      "alice@example.io",
+		"alice\u202e@example.io\t":          "alice@example.io",
+		"alice\x00@example.io":              "alice@example.io",
+		"alice example.io":                  "alice example.io",
+		"олександр@приклад.укр\u2066\u2069": "олександр@приклад.укр",
+	}
+	for in, want := range cases {
+		if got := Sanitize(in); got != want {
+			t.Errorf("Sanitize(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	obj := newObj("bob", "example.io", EmailPrefixObj{char: '+', text: "a\r\nb"})
+	if got := obj.SafeHeaderValue(); got != "bob+ab@example.io" {
+		t.Errorf("SafeHeaderValue() = %q", got)
+	}
+}
+
+func FuzzOutputs(f *testing.F) {
+	for _, s := range []string{
+		"user@example.com",
+		"bob+tag=x@example.io",
+		"bob+a\r\nb@example.io",
+		"bob@example.io\r\nBcc: eve@example.io",
+		"\"john doe\"@example.io",
+		"alice@[192.0.2.1]",
+		"олександр@приклад.укр",
+	} {
+		f.Add(s)
+	}
+
+	withParseConf(f, func(p *ConfigParseObj) {
+		p.EAI, p.Quoted, p.Literals = true, true, true
+	})
+
+	f.Fuzz(func(t *testing.T, mail string) {
+		for _, shot := range []bool{true, false} {
+			obj, err := parse(mail, shot)
+			if err != nil {
+				continue
+			}
+
+			back, err := Decode(obj.Bytes())
+			if err != nil {
+				t.Fatalf("Decode(Bytes(%q)): %v", mail, err)
+			}
+			for _, out := range []string{
+				obj.Login(), obj.LoginQuoted(), obj.Domain(), obj.Mail(), obj.MailFull(), obj.String(),
+				obj.SafeHeaderValue(), obj.Mailto(), NewMailbox("x", obj).String(), back.MailFull(),
+			} {
+				if strings.ContainsAny(out, "\r\n\x00") || controlIndex(out) >= 0 {
+					t.Fatalf("parse(%q): control characters in output %q", mail, out)
+				}
+			}
+		}
+	})
+}
+
+func TestProfiles(t *testing.T) {
+	cases := []struct {
+		in      string
+		profile ConfigParseObj
+		err     error
+	}{
+		{"bob@example.org", ProfilePragmatic, nil},
+		{"bob@пример.укр", ProfilePragmatic, nil},
+		{"bob@пример.укр", ProfileStrict, ErrInvalidDomainChars},
+		{"bob@mail.invalidtld", ProfileStrict, ErrUnknownTLD},
+		{"bob@co.uk", ProfileStrict, ErrPublicSuffix},
+		{"bob@example.com", ProfileStrict, ErrSpecialUse},
+		{"bob+tag@corp.io", ProfileStrict, nil},
+		{`"john doe"@corp.io`, ProfileStrict, ErrInvalidLoginChars},
+		{".bob..smith.@corp.io", ProfileStrict, nil},
+		{"bob..smith@corp.io", ProfilePragmatic, ErrInvalidLoginChars},
+		{"bоb@corp.io", ProfileStrict, ErrInvalidLoginChars},
+		{`"john doe"@corp.io`, ProfileRFC5321, nil},
+		{"bob@[192.0.2.1]", ProfileRFC5321, nil},
+		{"bob@[192.0.2.1]", ProfilePragmatic, ErrInvalidDomainChars},
+		{"bobby.s+news@gmail.com", ProfileProvider, nil},
+		{"bob@gmail.com", ProfileProvider, ErrProviderLogin},
+		{"bob_smith@gmail.com", ProfileProvider, ErrProviderLogin},
+		{strings.Repeat("a", 31) + "@gmail.com", ProfileProvider, ErrProviderLogin},
+		{"1bob@outlook.com", ProfileProvider, ErrProviderLogin},
+		{"bob_smith@outlook.com", ProfileProvider, nil},
+		{"bob@corp.io", ProfileProvider, nil},
+	}
+	for _, c := range cases {
+		_, err := NewWith(c.in, c.profile)
+		if !errors.Is(err, c.err) || c.err == nil && err != nil {
+			t.Errorf("NewWith(%q, %+v) = %v, want %v", c.in, c.profile, err, c.err)
+		}
+	}
+
+	var pe *ParseError
+	if _, err := NewWith("bob..s(m@corp.io", ProfileStrict); !errors.As(err, &pe) || pe.Offset != 6 {
+		t.Errorf("HTML5 error offset: %v", err)
+	}
+	if _, err := NewFastWith("bob_smith@gmail.com", ProfileProvider); !errors.As(err, &pe) ||
+		pe.Code != CodeProviderLogin || pe.Offset != 3 || pe.Component != ComponentLogin {
+		t.Errorf("provider error details: %v", err)
+	}
+
+	quotedProvider := ProfileProvider
+	quotedProvider.Quoted = true
+	for in, off := range map[string]int{`"bob_smith"@gmail.com`: 4, `"bo\b_smith"@gmail.com`: 5} {
+		if _, err := NewWith(in, quotedProvider); !errors.As(err, &pe) || pe.Offset != off || pe.Char != '_' {
+			t.Errorf("NewWith(%q): %v, want offset %d", in, err, off)
+		}
+	}
+	withParseConf(t, func(p *ConfigParseObj) { *p = quotedProvider })
+	if r := Validate(`"bob_smith"@gmail.com`); !r.Has(CodeProviderLogin) {
+		t.Errorf("Validate: %+v", r.Findings)
+	} else {
+		for _, f := range r.Findings {
+			if f.Code == CodeProviderLogin && f.Offset != 4 {
+				t.Errorf("Validate provider offset = %d", f.Offset)
+			}
+		}
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { *p = ProfileRFC5321 })
+	if _, err := New(`"john doe"@corp.io`); err != nil {
+		t.Errorf("profile from ConfigObj: %v", err)
+	}
+
+	AddProviderRule(ProviderRuleObj{MinLen: 8, MaxLen: 16}, "corp.io")
+	defer RemoveProviderRule("corp.io")
+	if _, err := NewWith("bob@corp.io", ProfileProvider); !errors.Is(err, ErrProviderLogin) {
+		t.Errorf("custom provider rule: %v", err)
+	}
+}
+
+// fuzzProfile seeds come from testdata/fuzz/FuzzProfile*.
+func fuzzProfile(f *testing.F, profile ConfigParseObj) {
+	f.Fuzz(func(t *testing.T, mail string) {
+		obj, err := NewWith(mail, profile)
+		if err != nil {
+			var pe *ParseError
+			if !errors.As(err, &pe) && !errors.Is(err, ErrLenMax) {
+				t.Fatalf("NewWith(%q): untyped error %v", mail, err)
+			}
+			return
+		}
+
+		again, err := NewWith(obj.MailFull(), profile)
+		if err != nil {
+			t.Fatalf("re‑parse(%q) under the same profile: %v", obj.MailFull(), err)
+		}
+		if again.MailFull() != obj.MailFull() {
+			t.Fatalf("re‑parse mismatch: %q vs %q", again.MailFull(), obj.MailFull())
+		}
+	})
+}
+
+func FuzzProfileStrict(f *testing.F) {
+	fuzzProfile(f, ProfileStrict)
+}
+
+func FuzzProfilePragmatic(f *testing.F) {
+	fuzzProfile(f, ProfilePragmatic)
+}
+
+func FuzzProfileRFC5321(f *testing.F) {
+	fuzzProfile(f, ProfileRFC5321)
+}
+
+func FuzzProfileProvider(f *testing.F) {
+	fuzzProfile(f, ProfileProvider)
+}
+
+func TestLenLimits(t *testing.T) {
+	login64 := strings.Repeat("a", 64)
+	cases := []struct {
+		in  string
+		set func(p *ConfigParseObj)
+		err error
+		off int
+	}{
+		{login64 + "@corp.io", nil, nil, 0},
+		{login64 + "a@corp.io", nil, ErrLoginLenMax, 64},
+		{strings.Repeat("a", 60) + "+tags@corp.io", nil, ErrLoginLenMax, 64},
+		{strings.Repeat("a", 200) + "@corp.io", nil, ErrLoginLenMax, 64},
+		{`"` + strings.Repeat("a", 62) + `"@corp.io`, func(p *ConfigParseObj) { p.Quoted = true }, nil, 0},
+		{`"` + strings.Repeat("a", 63) + `"@corp.io`, func(p *ConfigParseObj) { p.Quoted = true }, ErrLoginLenMax, 64},
+		{"bob@" + strings.Repeat("a", 63) + "." + strings.Repeat("b", 63) + ".io", func(p *ConfigParseObj) { p.LenMax = 128 }, ErrLenMax, 128},
+		{"bob@corp.io", func(p *ConfigParseObj) { p.LenMax = 8 }, ErrLenMax, 8},
+		{"bobby@corp.io", func(p *ConfigParseObj) { p.LoginLenMax = 4 }, ErrLoginLenMax, 4},
+		{"bob+x@corp.io", func(p *ConfigParseObj) { p.LoginLenMax = 4 }, ErrLoginLenMax, 4},
+		{login64 + "@corp.io", func(p *ConfigParseObj) { p.LoginLenMax = 500 }, nil, 0},
+		{login64 + "a@corp.io", func(p *ConfigParseObj) { p.LoginLenMax = 500 }, ErrLoginLenMax, 64},
+	}
+	for _, c := range cases {
+		p := ConfigParseObj{}
+		if c.set != nil {
+			c.set(&p)
+		}
+
+		_, err := NewWith(c.in, p)
+		if c.err == nil {
+			if err != nil {
+				t.Errorf("NewWith(%q): %v", c.in, err)
+			}
+			continue
+		}
+
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Err != c.err || pe.Offset != c.off {
+			t.Errorf("NewWith(%q) = %v, want %v at %d", c.in, err, c.err, c.off)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	r := Validate("Bo b@mailinator.invalidtld")
+	if r.Email != nil || r.Valid() || r.Worst() != SeverityError {
+		t.Fatalf("syntax error must make the report invalid: %+v", r)
+	}
+	if !r.Has(CodeInvalidLoginChars) || !r.Has(CodeUnknownTLD) {
+		t.Errorf("expected login and TLD findings: %+v", r.Findings)
+	}
+	for _, f := range r.Findings {
+		if f.Code == CodeInvalidLoginChars && (f.Offset != 2 || f.Severity != SeverityError) {
+			t.Errorf("login finding: %+v", f)
+		}
+		if f.Code == CodeUnknownTLD && (f.Offset != 5 || f.Severity != SeverityWarning) {
+			t.Errorf("TLD finding: %+v", f)
+		}
+	}
+
+	r = Validate("admin@mailinator.com")
+	if r.Email == nil || !r.Valid() || r.Worst() != SeverityWarning {
+		t.Fatalf("warnings only must keep the address: %+v", r)
+	}
+	if !r.Has(CodeDisposable) || !r.Has(CodeRoleLogin) {
+		t.Errorf("expected disposable and role findings: %+v", r.Findings)
+	}
+	if r.Email.Mail() != "admin@mailinator.com" {
+		t.Errorf("Email = %q", r.Email.Mail())
+	}
+
+	r = Validate("alice@gmial.com")
+	if !r.Has(CodeTypo) || r.Findings[0].Hint != "gmail.com" {
+		t.Errorf("typo finding: %+v", r.Findings)
+	}
+	if msg := Message(r.Findings[0].Err, "en"); msg != "The domain looks misspelled." {
+		t.Errorf("finding message: %q", msg)
+	}
+
+	r = Validate("bob@a@b..io")
+	if !r.Has(CodeManyA) || !r.Has(CodeInvalidDomainChars) {
+		t.Errorf("expected @ and domain findings: %+v", r.Findings)
+	}
+	for _, f := range r.Findings {
+		if f.Code == CodeInvalidDomainChars && f.Offset != 8 {
+			t.Errorf("domain finding offset = %d", f.Offset)
+		}
+	}
+
+	r = Validate("bob.smith@" + strings.Repeat("a", 250) + ".io")
+	if !r.Has(CodeLenMax) {
+		t.Errorf("expected length finding: %+v", r.Findings)
+	}
+	for _, f := range r.Findings {
+		if f.Code == CodeLenMax && f.Offset != 254 {
+			t.Errorf("length finding offset = %d, want the limit", f.Offset)
+		}
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.StrictTLD = true })
+	if r = Validate("bob@corp.invalidtld"); r.Valid() || r.Email != nil {
+		t.Errorf("enabled policy must be an error: %+v", r)
+	}
+	if r = Validate("bob.smith@corp.io"); len(r.Findings) != 0 || r.Email == nil {
+		t.Errorf("clean address: %+v", r.Findings)
+	}
+	if r = Validate("bob"); !r.Has(CodeEndToEOF) {
+		t.Errorf("missing @: %+v", r.Findings)
+	}
+}
+
+func TestParseBytes(t *testing.T) {
+	row := []byte("Bob+Dev=Go@Example.IO")
+
+	obj, err := NewBytes(row)
+	if err != nil || obj.MailFull() != "bob+dev=go@example.io" {
+		t.Fatalf("NewBytes: %v %v", obj, err)
+	}
+	copy(row, "XXXXXXXXXXXXXXXXXXXXX")
+	if obj.MailFull() != "bob+dev=go@example.io" {
+		t.Errorf("NewBytes must not keep a reference to its input: %q", obj.MailFull())
+	}
+	if obj, err := NewFastBytes([]byte("bob+dev@example.io")); err != nil || len(obj.Prefixes()) != 0 {
+		t.Errorf("NewFastBytes: %v %v", obj, err)
+	}
+	if _, err := NewBytes(nil); !errors.Is(err, ErrEndToEOF) {
+		t.Errorf("NewBytes(nil): %v", err)
+	}
+
+	var (
+		a, b  EmailObj
+		arena []byte
+	)
+	arena, err = AppendParse(arena, &a, []byte("Alice+x@Corp.io"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	arena, err = AppendParse(arena, &b, []byte("bob@пример.укр"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.MailFull() != "alice+x@corp.io" || b.Mail() != "bob@"+domainMust("пример.укр") {
+		t.Errorf("AppendParse: %q %q", a.MailFull(), b.Mail())
+	}
+	if !strings.Contains(string(arena), "alice") || !strings.Contains(string(arena), "corp.io") {
+		t.Errorf("arena does not hold the parsed strings: %q", arena)
+	}
+
+	if _, err = AppendParse(arena, &a, []byte("bad address")); err == nil {
+		t.Errorf("AppendParse must report errors")
+	}
+	if arena, err = AppendParse(arena[:0], &a, []byte("carol@corp.io")); err != nil || a.MailFull() != "carol@corp.io" || len(a.Prefixes()) != 0 {
+		t.Errorf("reused object: %q %v", a.MailFull(), err)
+	}
+}
+
+func TestParseCache(t *testing.T) {
+	oldConf, oldCache := conf, parseCache
+	withCache := *conf
+	withCache.NoCache = false
+	conf, parseCache = &withCache, newParseCacheObj(2)
+	t.Cleanup(func() { conf, parseCache = oldConf, oldCache })
+
+	a1, err := New("alice+x@corp.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, _ := New("alice+x@corp.io")
+	if a1 == a2 || &a1.prefixes[0] == &a2.prefixes[0] {
+		t.Fatalf("cache must return copies")
+	}
+	a1.prefixes[0].text = "mutated"
+	if a3, _ := New("alice+x@corp.io"); a3.MailFull() != "alice+x@corp.io" {
+		t.Errorf("a caller corrupted the cache: %q", a3.MailFull())
+	}
+
+	if fast, _ := NewFast("alice+x@corp.io"); len(fast.Prefixes()) != 0 {
+		t.Errorf("New and NewFast must not share entries")
+	}
+
+	_, err1 := New("bad address")
+	if err1 == nil {
+		t.Fatal("expected an error")
+	}
+	_, err2 := New("bad address")
+	if !errors.Is(err2, ErrEndToEOF) {
+		t.Errorf("cached failure: %v", err2)
+	}
+	if err1 == err2 {
+		t.Errorf("cache must return copies of parse errors")
+	}
+
+	st := CacheStats()
+	if st.Hits != 3 || st.Misses != 3 || st.Size != 2 || st.Capacity != 2 {
+		t.Errorf("stats = %+v", st)
+	}
+
+	// "alice+x@corp.io" (New) was the least recently used entry and got evicted.
+	New("alice+x@corp.io")
+	if st = CacheStats(); st.Misses != 4 {
+		t.Errorf("evicted entry must miss: %+v", st)
+	}
+
+	PurgeCache()
+	if st = CacheStats(); st.Size != 0 {
+		t.Errorf("purge: %+v", st)
+	}
+
+	if _, err := New(strings.Repeat("a", 1<<16) + "@corp.io"); !errors.Is(err, ErrLenMax) {
+		t.Errorf("huge input: %v", err)
+	}
+	if st = CacheStats(); st.Size != 0 {
+		t.Errorf("inputs over the length limit must not be cached: %+v", st)
+	}
+
+	withParseConf(t, func(p *ConfigParseObj) { p.RejectSpecialUse, p.Providers = true, true })
+	t.Cleanup(func() {
+		specialUseSet.mu.Lock()
+		delete(specialUseSet.data, "corp-internal.io")
+		specialUseSet.mu.Unlock()
+		RemoveProviderRule("corp-cache.io")
+	})
+	for range 2 {
+		if _, err := New("bob@corp-internal.io"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New("bob@corp-cache.io"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	AddSpecialUse("corp-internal.io")
+	if _, err := New("bob@corp-internal.io"); !errors.Is(err, ErrSpecialUse) {
+		t.Errorf("cached result survived AddSpecialUse: %v", err)
+	}
+	AddProviderRule(ProviderRuleObj{MinLen: 8}, "corp-cache.io")
+	if _, err := New("bob@corp-cache.io"); !errors.Is(err, ErrProviderLogin) {
+		t.Errorf("cached result survived AddProviderRule: %v", err)
+	}
+
+	gen := parseCache.generation()
+	PurgeCache()
+	parseCache.put(parseKeyObj{mail: "stale@corp.io"}, nil, ErrSpecialUse, gen)
+	if _, _, ok := parseCache.get(parseKeyObj{mail: "stale@corp.io"}); ok {
+		t.Errorf("a result parsed before a purge must not be stored")
+	}
+
+	conf.NoCache = true
+	if st = CacheStats(); st != (CacheStatsObj{}) {
+		t.Errorf("disabled cache must report zero stats: %+v", st)
+	}
+}
+
+func BenchmarkParseCached(b *testing.B) {
+	oldConf, oldCache := conf, parseCache
+	withCache := *conf
+	withCache.NoCache = false
+	conf, parseCache = &withCache, newParseCacheObj(10_000)
+	b.Cleanup(func() { conf, parseCache = oldConf, oldCache })
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		if _, err := New("user+tag@example.com"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
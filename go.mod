@@ -4,7 +4,9 @@ go 1.24
 
 require (
 	golang.org/x/crypto v0.40.0
+	golang.org/x/net v0.42.0
 	golang.org/x/sync v0.16.0
+	golang.org/x/text v0.27.0
 )
 
 require golang.org/x/sys v0.34.0 // indirect